@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/scanner"
+
+	"github.com/blakewilliams/overtime/internal/parser"
+)
+
+type (
+	// SourceError is returned when the generated code is not valid Go. It
+	// points at the offending line of the unformatted output and, when known,
+	// the schema element that produced it.
+	SourceError struct {
+		// Line and Column are the position of the error in the unformatted
+		// output.
+		Line   int
+		Column int
+		// Source is the offending line of the unformatted output.
+		Source string
+		// Origin describes the schema element that produced the line, it is
+		// empty when the line was not produced by one.
+		Origin string
+		Err    error
+	}
+
+	// originRecorder tracks which schema element produced each line of the
+	// rendered template, without adding anything to the output.
+	originRecorder struct {
+		buf     *bytes.Buffer
+		counted int
+		lines   int
+		origins []recordedOrigin
+	}
+
+	recordedOrigin struct {
+		line   int
+		origin string
+	}
+)
+
+func (e *SourceError) Error() string {
+	msg := "Generated invalid Go code"
+	if e.Origin != "" {
+		msg += " for " + e.Origin
+	}
+
+	return fmt.Sprintf("%s: %s\n\t%d | %s", msg, e.Err, e.Line, e.Source)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// origin returns the description of a schema element used in errors.
+func origin(element string, pos parser.Pos) string {
+	if pos.Line == 0 {
+		return element
+	}
+
+	return fmt.Sprintf("%s (%s)", element, pos)
+}
+
+func newOriginRecorder(buf *bytes.Buffer) *originRecorder {
+	return &originRecorder{buf: buf}
+}
+
+// record marks the next line written to the buffer as produced by origin. It
+// is called from templates and returns an empty string so nothing is
+// rendered.
+func (o *originRecorder) record(origin string) string {
+	written := o.buf.Bytes()
+	o.lines += bytes.Count(written[o.counted:], []byte("\n"))
+	o.counted = len(written)
+
+	o.origins = append(o.origins, recordedOrigin{line: o.lines + 1, origin: origin})
+
+	return ""
+}
+
+// at returns the origin of the closest recorded line at or before line.
+func (o *originRecorder) at(line int) string {
+	if o == nil {
+		return ""
+	}
+
+	found := ""
+	for _, recorded := range o.origins {
+		if recorded.line > line {
+			break
+		}
+
+		found = recorded.origin
+	}
+
+	return found
+}
+
+// sourceError wraps an error returned while parsing src with the offending
+// line and the schema element that produced it. Errors without a position
+// are returned as is.
+func sourceError(err error, src []byte, origins *originRecorder) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return err
+	}
+
+	pos := list[0].Pos
+	lines := bytes.Split(src, []byte("\n"))
+
+	source := ""
+	if pos.Line > 0 && pos.Line <= len(lines) {
+		source = string(bytes.TrimSpace(lines[pos.Line-1]))
+	}
+
+	return &SourceError{
+		Line:   pos.Line,
+		Column: pos.Column,
+		Source: source,
+		Origin: origins.at(pos.Line),
+		Err:    list[0],
+	}
+}
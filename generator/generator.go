@@ -12,23 +12,23 @@ import (
 	"github.com/blakewilliams/overtime/internal/parser"
 )
 
-var builtins = map[string]bool{
-	"int":     true,
-	"int64":   true,
-	"string":  true,
-	"bool":    true,
-	"float":   true,
-	"float64": true,
-}
+var builtins = parser.Builtins
+
+// DefaultPackageName is the name of the generated package when none is
+// configured.
+const DefaultPackageName = "overtime"
 
 type Go struct {
 	parser      *parser.Schema
 	PackageName string
+	// SchemaEndpoint makes NewCoordinator serve the schema endpoint without
+	// passing WithSchemaEndpoint.
+	SchemaEndpoint bool
 }
 
 func (g *Go) Endpoints() []Endpoint {
 	endpoints := make([]Endpoint, 0, len(g.parser.Endpoints))
-	for _, e := range g.parser.Endpoints {
+	for _, e := range g.parser.SortedEndpoints() {
 		endpoints = append(endpoints, Endpoint{endpoint: e, schema: g.parser})
 	}
 
@@ -36,9 +36,55 @@ func (g *Go) Endpoints() []Endpoint {
 }
 
 func (g *Go) Types() []GoType {
+	errorResponses := make(map[string]bool)
+	for _, e := range g.parser.Endpoints {
+		for _, response := range e.Responses {
+			if !response.IsSuccess() {
+				errorResponses[response.Body] = true
+			}
+		}
+	}
+
 	types := make([]GoType, 0, len(g.parser.Types))
-	for _, t := range g.parser.Types {
-		types = append(types, GoType{parserType: t})
+	for _, t := range g.parser.SortedTypes() {
+		types = append(types, GoType{
+			parserType:      t,
+			schema:          g.parser,
+			isErrorResponse: errorResponses[t.Name],
+		})
+	}
+
+	return types
+}
+
+// Enums returns the enums of the schema in declaration order.
+func (g *Go) Enums() []GoEnum {
+	enums := make([]GoEnum, 0, len(g.parser.Enums))
+	for _, enum := range g.parser.SortedEnums() {
+		enums = append(enums, GoEnum{parserEnum: enum})
+	}
+
+	return enums
+}
+
+// HasInputs returns true if any endpoint declares arguments.
+func (g *Go) HasInputs() bool {
+	for _, e := range g.Endpoints() {
+		if e.HasInput() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolvableTypes returns the types that have fields populated by resolvers.
+func (g *Go) ResolvableTypes() []GoType {
+	types := make([]GoType, 0, len(g.parser.Types))
+	for _, t := range g.Types() {
+		if t.NeedsResolver() {
+			types = append(types, t)
+		}
 	}
 
 	return types
@@ -55,34 +101,40 @@ func (g *Go) TypesNeedingResolvers() []GoResolver {
 }
 
 func NewGo(graph *parser.Schema) *Go {
-	return &Go{parser: graph, PackageName: "types"}
+	return &Go{parser: graph, PackageName: DefaultPackageName}
 }
 
-func (g *Go) Root() io.Reader {
+// Coordinator returns the generated server code. A SourceError is returned
+// when the schema produces code that is not valid Go.
+func (g *Go) Coordinator() (io.Reader, error) {
 	buf := new(bytes.Buffer)
+	origins := newOriginRecorder(buf)
 
-	buf.WriteString("// This file is generated only once to bootstrap the project\n")
-	buf.WriteString("// Your implementation for resolvers and endpoints should go here\n")
-	buf.WriteString(fmt.Sprintf("package %s\n\n", g.PackageName))
-
-	buf.WriteString("type RootResolver struct {}\n\n")
-	buf.WriteString("var _ Resolver = (*RootResolver)(nil)\n\n")
-	buf.WriteString("type RootController struct {}\n\n")
-	buf.WriteString("var _ Controller = (*RootController)(nil)\n\n")
-
-	return formatCode(buf)
-}
-
-func (g *Go) Coordinator() io.Reader {
-	template, err := template.New("server").Parse(`// Code generated by github.com/blakewilliams/overtime DO NOT EDIT
+	template, err := template.New("server").Funcs(template.FuncMap{"origin": origins.record}).Parse(`// Code generated by github.com/blakewilliams/overtime DO NOT EDIT
+	{{- if .SchemaVersion }}
+	// Schema version: {{ .SchemaVersion }}
+	{{- end }}
 
 	package {{.PackageName}}
 
 	import (
+		"context"
 		"net/http"
 		"encoding/json"
+		"errors"
+		"fmt"
+		"sync"
+		"io"
+		"net/url"
+		"reflect"
+		"strconv"
+		"strings"
 	)
 
+	// SchemaVersion is the version of the schema format the code was generated
+	// from, it is empty when the schema doesn't declare one.
+	const SchemaVersion = {{ printf "%q" .SchemaVersion }}
+
 	// Coordinator is the main entrypoint for the server and is responsible for
 	// routing requests to the correct endpoint and invoking the correct method
 	// on the controller. It also handles serializing the response and calling
@@ -91,48 +143,268 @@ func (g *Go) Coordinator() io.Reader {
 		mux 		http.ServeMux
 		resolver 	Resolver
 		controller 	Controller
+		errorHandler	ErrorHandler
+		maxConcurrency	int
+		schemaEndpoint	bool
+	}
+
+	// CoordinatorOption configures optional behavior of a Coordinator.
+	type CoordinatorOption func(*Coordinator)
+
+	// WithErrorHandler sets the ErrorHandler used to render errors returned
+	// while serving a request. DefaultErrorHandler is used when not set.
+	func WithErrorHandler(handler ErrorHandler) CoordinatorOption {
+		return func(c *Coordinator) {
+			c.errorHandler = handler
+		}
+	}
+
+	// WithMaxConcurrency limits how many resolvers run concurrently while
+	// resolving a single level of a response. Resolvers are not limited when
+	// not set.
+	func WithMaxConcurrency(maxConcurrency int) CoordinatorOption {
+		return func(c *Coordinator) {
+			c.maxConcurrency = maxConcurrency
+		}
+	}
+
+	// WithSchemaEndpoint serves a description of the schema, including its
+	// version and endpoints, as JSON from GET /_overtime/schema.
+	func WithSchemaEndpoint() CoordinatorOption {
+		return func(c *Coordinator) {
+			c.schemaEndpoint = true
+		}
 	}
 
 	// NewCoordinator returns a new Coordinator that passes requests to the
 	// provided resolver and controller.
-	func NewCoordinator(resolver Resolver, controller Controller) *Coordinator {
+	{{- if .SchemaEndpoint }} The schema endpoint
+	// is always served.
+	{{- end }}
+	func NewCoordinator(resolver Resolver, controller Controller, opts ...CoordinatorOption) *Coordinator {
 		c := &Coordinator{
 			mux: http.ServeMux{},
 			resolver: resolver,
 			controller: controller,
+			errorHandler: DefaultErrorHandler{},
+			{{- if .SchemaEndpoint }}
+			schemaEndpoint: true,
+			{{- end }}
+		}
+
+		for _, opt := range opts {
+			opt(c)
 		}
 
 		{{ range $key, $value := .Endpoints }}
-		c.mux.HandleFunc("{{.Method }} {{.Path}}", func(w http.ResponseWriter, r *http.Request) {
-			result, err := c.controller.{{ .MethodName }}(w, r)
+		{{ origin .Origin }}c.mux.HandleFunc("{{.Method }} {{.Path}}", func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+			{{- if .HasInput }}
+			input, err := decode{{ .InputName }}(r)
 			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				// TODO add error handler
+				c.errorHandler.HandleError(w, r, err)
 				return
 			}
-			{{ if .ResolverMethod }}
-				{{ .ResolverMethod }}
-			{{ end }}
 
-			w.WriteHeader(http.StatusOK)
-			w.Header().Set("Content-Type", "application/json")
-			err = json.NewEncoder(w).Encode(result)
+			{{- end }}
+
+			{{ .ControllerCall }}
 			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+				{{- range .ErrorResponses }}
+				var {{ .VarName }} *{{ .TypeName }}
+				if errors.As(err, &{{ .VarName }}) {
+					c.writeResponse(w, r, {{ .StatusCode }}, {{ .VarName }})
+					return
+				}
+				{{ end }}
+
+				c.errorHandler.HandleError(w, r, err)
 				return
 			}
+			{{ if .ResolverMethod }}
+			if err := {{ .ResolverMethod }}; err != nil {
+				c.errorHandler.HandleError(w, r, err)
+				return
+			}
+			{{ end }}
+
+			{{- if .HasBody }}
+			c.writeResponse(w, r, {{ .StatusCode }}, result)
+			{{- else }}
+			w.WriteHeader({{ .StatusCode }})
+			{{- end }}
 		})
 		{{ end }}
 
+		if c.schemaEndpoint {
+			c.mux.HandleFunc("GET /_overtime/schema", func(w http.ResponseWriter, r *http.Request) {
+				c.writeResponse(w, r, http.StatusOK, schemaDescription)
+			})
+		}
+
 		return c
 	}
 
+	// schemaEndpointDescription describes a single endpoint of the schema
+	// served by WithSchemaEndpoint.
+	type schemaEndpointDescription struct {
+		Name	string	` + "`" + `json:"name"` + "`" + `
+		Method	string	` + "`" + `json:"method"` + "`" + `
+		Path	string	` + "`" + `json:"path"` + "`" + `
+	}
+
+	var schemaDescription = struct {
+		Version		string				` + "`" + `json:"version"` + "`" + `
+		Endpoints	[]schemaEndpointDescription	` + "`" + `json:"endpoints"` + "`" + `
+	}{
+		Version: SchemaVersion,
+		Endpoints: []schemaEndpointDescription{
+			{{- range .Endpoints }}
+			{Name: "{{ .MethodName }}", Method: "{{ .Method }}", Path: {{ printf "%q" .Path }}},
+			{{- end }}
+		},
+	}
+
 	// ServeHTTP serves the provided request by routing it to the correct
 	// endpoint and invoking the correct method on the controller.
 	func (c *Coordinator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.mux.ServeHTTP(w, r)
 	}
 
+	type requestContextKey struct{}
+
+	// RequestFromContext returns the request being served from the context
+	// passed to controllers and resolvers, giving access to headers and other
+	// request details.
+	func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+		r, ok := ctx.Value(requestContextKey{}).(*http.Request)
+		return r, ok
+	}
+
+	// writeResponse encodes the body before writing the status so that
+	// encoding failures can still be rendered by the error handler.
+	func (c *Coordinator) writeResponse(w http.ResponseWriter, r *http.Request, status int, body any) {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(encoded)
+	}
+
+	/*******************************************************************************************
+	* Errors generated here
+	*******************************************************************************************/
+
+	// HTTPError is an error that controls the response sent to the client. It
+	// can be returned by controllers and resolvers to respond with a status
+	// other than 500.
+	type HTTPError struct {
+		Status  int
+		Code    string
+		Message string
+	}
+
+	// NewHTTPError returns an HTTPError with the given status, machine readable
+	// code, and human readable message.
+	func NewHTTPError(status int, code string, message string) *HTTPError {
+		return &HTTPError{Status: status, Code: code, Message: message}
+	}
+
+	// NotFound returns an HTTPError that responds with a 404.
+	func NotFound(message string) *HTTPError {
+		return NewHTTPError(http.StatusNotFound, "not_found", message)
+	}
+
+	// Unauthorized returns an HTTPError that responds with a 401.
+	func Unauthorized(message string) *HTTPError {
+		return NewHTTPError(http.StatusUnauthorized, "unauthorized", message)
+	}
+
+	// Forbidden returns an HTTPError that responds with a 403.
+	func Forbidden(message string) *HTTPError {
+		return NewHTTPError(http.StatusForbidden, "forbidden", message)
+	}
+
+	// Conflict returns an HTTPError that responds with a 409.
+	func Conflict(message string) *HTTPError {
+		return NewHTTPError(http.StatusConflict, "conflict", message)
+	}
+
+	// NotImplemented returns an HTTPError that responds with a 501, it is
+	// returned by the stubs added to impl.go for new methods.
+	func NotImplemented(method string) *HTTPError {
+		return NewHTTPError(http.StatusNotImplemented, "not_implemented", method+" is not implemented")
+	}
+
+	func (e *HTTPError) Error() string {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+	}
+
+	// ErrorHandler renders errors returned by input decoding, controllers, and
+	// resolvers.
+	type ErrorHandler interface {
+		HandleError(w http.ResponseWriter, r *http.Request, err error)
+	}
+
+	// ErrorHandlerFunc allows a plain function to be used as an ErrorHandler.
+	type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+
+	func (f ErrorHandlerFunc) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+		f(w, r, err)
+	}
+
+	// ErrorResponse is the JSON envelope DefaultErrorHandler responds with.
+	type ErrorResponse struct {
+		Error ErrorDetails ` + "`" + `json:"error"` + "`" + `
+	}
+
+	// ErrorDetails describes the error in an ErrorResponse.
+	type ErrorDetails struct {
+		Code    string ` + "`" + `json:"code"` + "`" + `
+		Message string ` + "`" + `json:"message"` + "`" + `
+		{{- if .HasInputs }}
+		Fields  []InvalidField ` + "`" + `json:"fields,omitempty"` + "`" + `
+		{{- end }}
+	}
+
+	// DefaultErrorHandler renders errors as an ErrorResponse. HTTPError values
+	// use their own status, code, and message while any other error responds
+	// with a 500 without exposing the underlying error to the client.
+	type DefaultErrorHandler struct{}
+
+	func (DefaultErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+		status := http.StatusInternalServerError
+		details := ErrorDetails{Code: "internal_error", Message: "Internal server error"}
+
+		var httpErr *HTTPError
+		{{- if .HasInputs }}
+		var inputErr *InputError
+		{{- end }}
+
+		switch {
+		case errors.As(err, &httpErr):
+			status = httpErr.Status
+			details = ErrorDetails{Code: httpErr.Code, Message: httpErr.Message}
+		{{- if .HasInputs }}
+		case errors.As(err, &inputErr):
+			status = http.StatusBadRequest
+			details = ErrorDetails{
+				Code: "invalid_input",
+				Message: "The request contains invalid input",
+				Fields: inputErr.Fields,
+			}
+		{{- end }}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: details})
+	}
+
 	/*******************************************************************************************
 	* Controllers generated here
 	*******************************************************************************************/
@@ -142,18 +414,298 @@ func (g *Go) Coordinator() io.Reader {
 			{{- if .Comment }}
 		 	{{- .Comment }}
 			{{ end }}
+			{{- .ResponsesComment }}
+			{{ .MethodName }}({{ .Arguments }}) {{ .Results }}
+		{{ end }}
+	}
+
+	{{ if .HasInputs }}
+	/*******************************************************************************************
+	* Inputs generated here
+	*******************************************************************************************/
+
+	{{ range $key, $value := .Endpoints }}
+		{{ if .HasInput }}
+		{{ origin .Origin }}// {{ .InputName }} holds the decoded arguments for {{ .MethodName }}.
+		type {{ .InputName }} struct {
+			{{ range $input := .Inputs }}
+				{{- origin $input.Origin }}{{ $input.Name }} {{ $input.Type }} {{ $input.Tags }}
+			{{ end }}
+		}
 
-			{{- .MethodName }}(w http.ResponseWriter, r *http.Request) ({{ .ReturnValue }}, error)
+		func decode{{ .InputName }}(r *http.Request) (*{{ .InputName }}, error) {
+			d := newInputDecoder(r, {{ .HasBodyInput }})
+			input := &{{ .InputName }}{}
+
+			{{ range $input := .Inputs }}
+				{{ origin $input.Origin }}input.{{ $input.Name }} = {{ $input.Decoder }}
+			{{- end }}
+
+			return input, d.err()
+		}
 		{{ end }}
+	{{ end }}
+
+	// InvalidField describes a single input field that failed validation.
+	type InvalidField struct {
+		Field   string ` + "`" + `json:"field"` + "`" + `
+		Source  string ` + "`" + `json:"source"` + "`" + `
+		Message string ` + "`" + `json:"message"` + "`" + `
+	}
+
+	// InputError is returned when a request contains invalid input. It lists
+	// every invalid field so clients can correct all of them at once.
+	type InputError struct {
+		Fields []InvalidField ` + "`" + `json:"fields"` + "`" + `
+	}
+
+	func (e *InputError) Error() string {
+		messages := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			messages[i] = fmt.Sprintf("%s %s %s", field.Source, field.Field, field.Message)
+		}
+
+		return "invalid input: " + strings.Join(messages, ", ")
+	}
+
+	// inputDecoder reads the arguments of an endpoint from a request, recording
+	// every invalid field instead of stopping at the first one.
+	type inputDecoder struct {
+		r      *http.Request
+		query  url.Values
+		body   map[string]json.RawMessage
+		fields []InvalidField
+	}
+
+	func newInputDecoder(r *http.Request, hasBody bool) *inputDecoder {
+		d := &inputDecoder{r: r, query: r.URL.Query()}
+
+		if hasBody {
+			err := json.NewDecoder(r.Body).Decode(&d.body)
+			if err != nil && !errors.Is(err, io.EOF) {
+				d.invalid("body", "", "must be a JSON object")
+			}
+		}
+
+		return d
+	}
+
+	func (d *inputDecoder) invalid(source string, name string, message string) {
+		d.fields = append(d.fields, InvalidField{Field: name, Source: source, Message: message})
+	}
+
+	func (d *inputDecoder) err() error {
+		if len(d.fields) == 0 {
+			return nil
+		}
+
+		return &InputError{Fields: d.fields}
+	}
+
+	func pathParam[T any](d *inputDecoder, name string) T {
+		value, err := parseParam[T](d.r.PathValue(name))
+		if err != nil {
+			d.invalid("path", name, "must be "+describeType[T]())
+		}
+
+		return value
+	}
+
+	func queryParam[T any](d *inputDecoder, name string) T {
+		var value T
+		if !d.query.Has(name) {
+			d.invalid("query", name, "is required")
+			return value
+		}
+
+		value, err := parseParam[T](d.query.Get(name))
+		if err != nil {
+			d.invalid("query", name, "must be "+describeType[T]())
+		}
+
+		return value
 	}
 
+	func optionalQueryParam[T any](d *inputDecoder, name string) *T {
+		if !d.query.Has(name) {
+			return nil
+		}
+
+		value, err := parseParam[T](d.query.Get(name))
+		if err != nil {
+			d.invalid("query", name, "must be "+describeType[T]())
+			return nil
+		}
+
+		return &value
+	}
+
+	func bodyField[T any](d *inputDecoder, name string, required bool) T {
+		var value T
+		raw, ok := d.body[name]
+		if !ok || string(raw) == "null" {
+			if required {
+				d.invalid("body", name, "is required")
+			}
+
+			return value
+		}
+
+		if err := json.Unmarshal(raw, &value); err != nil {
+			d.invalid("body", name, "must be "+describeType[T]())
+		}
+
+		return value
+	}
+
+	func optionalBodyField[T any](d *inputDecoder, name string) *T {
+		if raw, ok := d.body[name]; !ok || string(raw) == "null" {
+			return nil
+		}
+
+		value := bodyField[T](d, name, false)
+		return &value
+	}
+
+	// enumInput is implemented by the enum types of the schema so inputs can
+	// be checked against their declared values.
+	type enumInput interface {
+		parse(value string) error
+		describe() string
+	}
+
+	// parseParam converts the raw string value of a path or query parameter
+	// into the type of the input field it populates.
+	func parseParam[T any](raw string) (T, error) {
+		var value T
+		var err error
+
+		switch v := any(&value).(type) {
+		case *string:
+			*v = raw
+		case *int:
+			*v, err = strconv.Atoi(raw)
+		case *int64:
+			*v, err = strconv.ParseInt(raw, 10, 64)
+		case *bool:
+			*v, err = strconv.ParseBool(raw)
+		case *float64:
+			*v, err = strconv.ParseFloat(raw, 64)
+		case enumInput:
+			err = v.parse(raw)
+		default:
+			err = fmt.Errorf("unsupported parameter type %T", value)
+		}
+
+		return value, err
+	}
+
+	// describeType returns a human readable description of the expected type
+	// of an input field for validation messages.
+	func describeType[T any]() string {
+		var value T
+
+		if enum, ok := any(&value).(enumInput); ok {
+			return enum.describe()
+		}
+
+		// Lists of enums are described by their values too, since the
+		// elements are checked against them when decoding the list.
+		if t := reflect.TypeOf(value); t != nil && t.Kind() == reflect.Slice {
+			if enum, ok := reflect.New(t.Elem()).Interface().(enumInput); ok {
+				return "a list of values that are each " + enum.describe()
+			}
+		}
+
+		switch any(value).(type) {
+		case string:
+			return "a string"
+		case int, int64:
+			return "an integer"
+		case bool:
+			return "a boolean"
+		case float64:
+			return "a number"
+		default:
+			return "valid JSON"
+		}
+	}
+	{{ end }}
+
+	{{ if .Enums }}
+	/*******************************************************************************************
+	* Enums generated here
+	*******************************************************************************************/
+
+	{{ range $enum := .Enums }}
+		{{ origin .Origin }}
+		{{- if .Comment }}
+		{{- .Comment }}
+		{{ end }}
+		{{- "" }}type {{ .Name }} string
+
+		const (
+			{{- range .Values }}
+			{{ .ConstantName }} {{ $enum.Name }} = {{ printf "%q" .Value }}
+			{{- end }}
+		)
+
+		// IsValid returns true if the value is one of the declared {{ .Name }}
+		// values.
+		func (e {{ .Name }}) IsValid() bool {
+			switch e {
+			case {{ range $i, $value := .Values }}{{ if $i }}, {{ end }}{{ $value.ConstantName }}{{ end }}:
+				return true
+			default:
+				return false
+			}
+		}
+
+		// MarshalJSON encodes the value as a JSON string, returning an error when
+		// it is not one of the declared values.
+		func (e {{ .Name }}) MarshalJSON() ([]byte, error) {
+			if !e.IsValid() {
+				return nil, fmt.Errorf("invalid {{ .Name }} %q, must be %s", string(e), e.describe())
+			}
+
+			return json.Marshal(string(e))
+		}
+
+		// UnmarshalJSON decodes a JSON string, returning an error when it is not
+		// one of the declared values.
+		func (e *{{ .Name }}) UnmarshalJSON(data []byte) error {
+			var value string
+			if err := json.Unmarshal(data, &value); err != nil {
+				return err
+			}
+
+			return e.parse(value)
+		}
+
+		func (e *{{ .Name }}) parse(value string) error {
+			if !{{ .Name }}(value).IsValid() {
+				return fmt.Errorf("invalid {{ .Name }} %q, must be %s", value, e.describe())
+			}
+
+			*e = {{ .Name }}(value)
+
+			return nil
+		}
+
+		func ({{ .Name }}) describe() string {
+			return {{ printf "%q" .Description }}
+		}
+	{{ end }}
+	{{ end }}
+
 	/*******************************************************************************************
 	* Types generated here
 	*******************************************************************************************/
 
 	{{ range $key, $value := .Types }}
-		type {{ .Name }} struct {
+		{{ origin .Origin }}type {{ .Name }} struct {
 			{{ range $field := .Fields }}
+				{{- origin $field.Origin }}
 				{{- if $field.Comment }}
 				{{- $field.Comment }}
 				{{ end }}
@@ -161,42 +713,242 @@ func (g *Go) Coordinator() io.Reader {
 			{{ end }}
 		}
 
+		{{ if .IsErrorResponse }}
+		// Error allows {{ .Name }} to be returned by controllers declaring it as
+		// a response.
+		func (e *{{ .Name }}) Error() string {
+			return {{ .ErrorMessage }}
+		}
+		{{ end }}
+
 		{{ if .NeedsResolver }}
-		func ResolveFor{{ .Name }}(records []*{{ .Name }}, resolver Resolver) (error) {
-			ids := make([]int64, len(records))
-			recordsMap := make({{.MapType }}, len(records))
+		// ResolveFor{{ .Name }} populates the resolver fields of the given records and
+		// of every record nested within them. Records are resolved level by level
+		// so each resolver is called once per level of nesting. Independent
+		// resolvers run concurrently, at most maxConcurrency at a time, or without a
+		// limit when maxConcurrency is 0. Resolution stops as soon as ctx is done.
+		func ResolveFor{{ .Name }}(ctx context.Context, records []*{{ .Name }}, resolver Resolver, maxConcurrency int) (error) {
+			return resolveLevels(ctx, &resolutionLevel{ {{- .Name }}: records}, resolver, maxConcurrency)
+		}
 
-			for i, record := range records {
-				ids[i] = record.ID
-				recordsMap[record.ID] = record
+		// resolve{{ .Name }}Fields schedules the resolvers populating the fields of
+		// records and adds the resolved records that need resolving themselves to
+		// the next level.
+		func resolve{{ .Name }}Fields(records []*{{ .Name }}, resolver Resolver, group *resolverGroup, next *resolutionLevel) {
+			ids := make([]{{ .IDType }}, 0, len(records))
+			seen := make(map[{{ .IDType }}]bool, len(records))
+
+			for _, record := range records {
+				if record != nil && !seen[record.ID] {
+					seen[record.ID] = true
+					ids = append(ids, record.ID)
+				}
 			}
 
 			{{ range $field := .Fields }}
-				{{ if not $field.IsBuiltin }}
-					res, err := resolver.{{ $field.ResolverMethodName }}(ids)
+				{{- if not $field.IsScalar }}
+				{{ origin $field.Origin }}group.Go(func(ctx context.Context) error {
+					res, err := resolver.{{ $field.ResolverMethodName }}(ctx, ids)
 					if err != nil {
 						return err
 					}
 
-					for id, record := range recordsMap {
-						if val, ok := res[id]; ok {
+					for _, record := range records {
+						if record == nil {
+							continue
+						}
+
+						if val, ok := res[record.ID]; ok {
 							record.{{ $field.Name }} = val
+							{{- if $field.NextLevel }}
+							{{ $field.NextLevel }}
+							{{- end }}
 						}
 					}
-				{{ end }}
-			{{ end }}
 
-			return nil
+					return nil
+				})
+				{{ end }}
+			{{- end }}
 		}
 		{{ end }}
 	{{ end }}
 
+	{{ if .ResolvableTypes }}
+	// maxResolutionDepth limits how deeply nested records are resolved, guarding
+	// against resolvers that keep returning records never seen before.
+	const maxResolutionDepth = 32
+
+	// resolutionLevel holds the records found at a single level of nesting
+	// that still need their resolver fields populated.
+	type resolutionLevel struct {
+		mu sync.Mutex
+		{{- range .ResolvableTypes }}
+		{{ .Name }} []*{{ .Name }}
+		{{- end }}
+	}
+
+	func (l *resolutionLevel) empty() bool {
+		return {{ range $i, $type := .ResolvableTypes }}{{ if $i }} && {{ end }}len(l.{{ $type.Name }}) == 0{{ end }}
+	}
+
+	{{ range .ResolvableTypes }}
+	func (l *resolutionLevel) add{{ .Name }}(records ...*{{ .Name }}) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.{{ .Name }} = append(l.{{ .Name }}, records...)
+	}
+	{{ end }}
+
+	// resolvedRecords holds the ids of the records resolved at earlier levels,
+	// so records referring back to them, like the posts of a post's author, are
+	// not resolved again and types referring to each other stop resolving.
+	type resolvedRecords struct {
+		{{- range .ResolvableTypes }}
+		{{ .Name }} map[{{ .IDType }}]bool
+		{{- end }}
+	}
+
+	{{ range .ResolvableTypes }}
+	// filter{{ .Name }} returns the records that were not resolved at an earlier
+	// level and marks them as resolved.
+	func (r *resolvedRecords) filter{{ .Name }}(records []*{{ .Name }}) []*{{ .Name }} {
+		if r.{{ .Name }} == nil {
+			r.{{ .Name }} = make(map[{{ .IDType }}]bool, len(records))
+		}
+
+		unresolved := make([]*{{ .Name }}, 0, len(records))
+		for _, record := range records {
+			if record != nil && !r.{{ .Name }}[record.ID] {
+				unresolved = append(unresolved, record)
+			}
+		}
+
+		// Records are only marked once the level is filtered, so copies of a
+		// record within the same level are all resolved.
+		for _, record := range unresolved {
+			r.{{ .Name }}[record.ID] = true
+		}
+
+		return unresolved
+	}
+	{{ end }}
+
+	// resolveLevels resolves the records of each level, batching every record
+	// of a type into a single call per resolver, until no records remain.
+	// Records already resolved at an earlier level are skipped. The resolvers
+	// of a level run concurrently and every error they return is reported.
+	func resolveLevels(ctx context.Context, level *resolutionLevel, resolver Resolver, maxConcurrency int) error {
+		resolved := &resolvedRecords{}
+
+		for depth := 0; !level.empty(); depth++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if depth == maxResolutionDepth {
+				return fmt.Errorf("resolving records exceeded %d levels of nesting", maxResolutionDepth)
+			}
+
+			next := &resolutionLevel{}
+			group := newResolverGroup(ctx, maxConcurrency)
+			{{- range .ResolvableTypes }}
+			if records := resolved.filter{{ .Name }}(level.{{ .Name }}); len(records) > 0 {
+				resolve{{ .Name }}Fields(records, resolver, group, next)
+			}
+			{{- end }}
+
+			if err := group.Wait(); err != nil {
+				return err
+			}
+
+			level = next
+		}
+
+		return nil
+	}
+
+	// resolverGroup runs resolver calls concurrently, limiting how many run at
+	// once and collecting every error returned. The context passed to calls is
+	// canceled when the request is done or any call fails, so the remaining
+	// calls can stop early.
+	type resolverGroup struct {
+		ctx    context.Context
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+		sem    chan struct{}
+		mu     sync.Mutex
+		errs   []error
+	}
+
+	func newResolverGroup(ctx context.Context, maxConcurrency int) *resolverGroup {
+		ctx, cancel := context.WithCancel(ctx)
+		group := &resolverGroup{ctx: ctx, cancel: cancel}
+		if maxConcurrency > 0 {
+			group.sem = make(chan struct{}, maxConcurrency)
+		}
+
+		return group
+	}
+
+	func (g *resolverGroup) Go(fn func(ctx context.Context) error) {
+		g.wg.Add(1)
+
+		go func() {
+			defer g.wg.Done()
+
+			if g.sem != nil {
+				select {
+				case g.sem <- struct{}{}:
+					defer func() { <-g.sem }()
+				case <-g.ctx.Done():
+					g.fail(g.ctx.Err())
+					return
+				}
+			}
+
+			if err := g.ctx.Err(); err != nil {
+				g.fail(err)
+				return
+			}
+
+			if err := fn(g.ctx); err != nil {
+				g.fail(err)
+			}
+		}()
+	}
+
+	// fail records err and cancels the remaining calls. Cancellation errors
+	// caused by an earlier failure are not recorded.
+	func (g *resolverGroup) fail(err error) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if len(g.errs) > 0 && errors.Is(err, context.Canceled) {
+			return
+		}
+
+		g.errs = append(g.errs, err)
+		g.cancel()
+	}
+
+	// Wait blocks until every scheduled call returns and joins their errors.
+	func (g *resolverGroup) Wait() error {
+		g.wg.Wait()
+		g.cancel()
+
+		return errors.Join(g.errs...)
+	}
+	{{ end }}
+
 	/*******************************************************************************************
 	* Resolvers generated here
 	*******************************************************************************************/
 
 	type Resolver interface {
 		{{ range $key, $value := .Resolvers }}
+			{{- origin .Origin }}
 			{{- if .Comment }}
 			{{- .Comment }}
 			{{ end }}
@@ -206,23 +958,26 @@ func (g *Go) Coordinator() io.Reader {
 	`)
 
 	if err != nil {
-		panic(fmt.Errorf("failed to generate server template: %w", err))
+		return nil, fmt.Errorf("Failed to parse the server template: %w", err)
 	}
 
-	buf := new(bytes.Buffer)
-
 	err = template.Execute(buf, map[string]interface{}{
-		"PackageName": g.PackageName,
-		"Endpoints":   g.Endpoints(),
-		"Types":       g.Types(),
-		"Resolvers":   g.TypesNeedingResolvers(),
+		"PackageName":     g.PackageName,
+		"SchemaVersion":   g.parser.Version,
+		"HasInputs":       g.HasInputs(),
+		"Endpoints":       g.Endpoints(),
+		"Types":           g.Types(),
+		"Enums":           g.Enums(),
+		"ResolvableTypes": g.ResolvableTypes(),
+		"Resolvers":       g.TypesNeedingResolvers(),
+		"SchemaEndpoint":  g.SchemaEndpoint,
 	})
 
 	if err != nil {
-		panic(fmt.Errorf("failed to execute server template: %w", err))
+		return nil, fmt.Errorf("Failed to render the server template: %w", err)
 	}
 
-	return formatCode(buf)
+	return formatCode(buf, origins)
 }
 
 func uncapitalize(s string) string {
@@ -235,23 +990,23 @@ func uncapitalize(s string) string {
 	return string(append([]rune{unicode.ToLower(r[0])}, r[1:]...))
 }
 
-func capitalize(s string) string {
-	if len(s) == 0 {
-		return s
+// formatCode removes unused imports from the generated code and formats it.
+// Errors point at the schema element recorded by origins for the offending
+// line, origins can be nil for code not produced from the schema.
+func formatCode(b *bytes.Buffer, origins *originRecorder) (io.Reader, error) {
+	pruned, err := pruneImports(b.Bytes())
+	if err != nil {
+		return nil, sourceError(err, b.Bytes(), origins)
 	}
 
-	r := []rune(s)
-
-	return string(append([]rune{unicode.ToUpper(r[0])}, r[1:]...))
-}
-
-func formatCode(b *bytes.Buffer) io.Reader {
-	formatted, err := format.Source(b.Bytes())
+	// Removing imports shifts the lines, so origins no longer apply. The
+	// source parsed above, so this only fails if pruning broke it.
+	formatted, err := format.Source(pruned)
 	if err != nil {
-		panic(err)
+		return nil, sourceError(err, pruned, nil)
 	}
 
-	return bytes.NewReader(formatted)
+	return bytes.NewReader(formatted), nil
 }
 
 func formatComment(s string) string {
@@ -1,11 +1,13 @@
 package generator
 
 import (
+	"flag"
 	goparser "go/parser"
 	"go/token"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -14,71 +16,275 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
 func TestCodeGen(t *testing.T) {
 	schema, err := parser.Parse(strings.NewReader(`
 types:
-    Comment:
+    User:
         fields:
             id:	int64
+            name: string
+    Comment:
+        fields:
+            id:	string
             body: string
+            author: User
     Post:
         fields:
             id:	int64
             body: string
             comments: "[]Comment"
+    NotFoundError:
+        fields:
+            message: string
 endpoints:
     "GET /api/v1/comments/:commentID":
         name: GetCommentByID
+        input:
+            commentID: string
         response:
             status: 200
             body: Comment
+    "GET /api/v1/posts":
+        name: ListPosts
+        input:
+            page?: int
+        response:
+            status: 200
+            body: "[]Post"
+    "POST /api/v1/posts":
+        name: CreatePost
+        input:
+            body: string
+            draft?: bool
+        response:
+            status: 201
+            body: Post
     "GET /api/v1/posts/:postID":
         name: GetPostByID
+        input:
+            postID: int64
+        responses:
+            200: Post
+            404: NotFoundError
+    "DELETE /api/v1/posts/:postID":
+        name: DeletePost
+        input:
+            postID: int64
         response:
-            status: 200
-            body: Post`))
+            status: 204`))
 
 	require.NoError(t, err)
 
 	gen := NewGo(schema)
 	gen.PackageName = "mytypes"
 
-	writer := gen.Coordinator()
+	writer, err := gen.Coordinator()
+	require.NoError(t, err)
+
 	out, err := io.ReadAll(writer)
 	require.NoError(t, err)
 
 	require.Contains(t, string(out), `package mytypes`)
 	require.Contains(t, string(out), "type Coordinator struct")
 	require.Contains(t, string(out), "GET /api/v1/comments/{commentID}")
-	require.Contains(t, string(out), "result, err := c.controller.GetCommentByID(w, r)")
-	require.Contains(t, string(out), "ResolveForPost([]*Post{result}, c.resolver)")
+	require.Contains(t, string(out), "input, err := decodeGetCommentByIDInput(r)")
+	require.Contains(t, string(out), "result, err := c.controller.GetCommentByID(ctx, input)")
+	require.Contains(t, string(out), "ResolveForPost(ctx, []*Post{result}, c.resolver, c.maxConcurrency)")
 
 	// HandleFunc
 	require.Contains(t, string(out), `HandleFunc("GET /api/v1/comments/{commentID}", func(w http.ResponseWriter, r *http.Request)`)
-	require.Contains(t, string(out), "GetCommentByID(w http.ResponseWriter, r *http.Request)")
 
 	// controller tests
 	require.Contains(t, string(out), `package mytypes`)
-	require.Contains(t, string(out), "GetCommentByID(w http.ResponseWriter, r *http.Request)")
+	require.Contains(t, string(out), "GetCommentByID(ctx context.Context, input *GetCommentByIDInput) (*Comment, error)")
+	require.Contains(t, string(out), "ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error)")
+
+	// input tests
+	require.Regexp(t, regexp.MustCompile("CommentID\\s+string\\s+`json:\"-\"`"), string(out))
+	require.Regexp(t, regexp.MustCompile("Page\\s+\\*int\\s+`json:\"-\"`"), string(out))
+	require.Contains(t, string(out), `input.CommentID = pathParam[string](d, "commentID")`)
+	require.Contains(t, string(out), `input.Page = optionalQueryParam[int](d, "page")`)
+	require.Contains(t, string(out), `input.Body = bodyField[string](d, "body", true)`)
+	require.Contains(t, string(out), `input.Draft = optionalBodyField[bool](d, "draft")`)
+	require.Contains(t, string(out), "d := newInputDecoder(r, true)")
+
+	// response tests
+	require.Contains(t, string(out), "c.writeResponse(w, r, http.StatusCreated, result)")
+	require.Contains(t, string(out), "c.writeResponse(w, r, http.StatusNotFound, notFoundErrorResponse)")
+	require.Contains(t, string(out), "w.WriteHeader(http.StatusNoContent)")
+	require.Contains(t, string(out), "DeletePost(ctx context.Context, input *DeletePostInput) error")
+	require.Contains(t, string(out), "// Responds with 200 Post, 404 NotFoundError")
+	require.Contains(t, string(out), "func (e *NotFoundError) Error() string")
+
+	// error handling tests
+	require.Contains(t, string(out), "type HTTPError struct")
+	require.Contains(t, string(out), "type ErrorHandler interface")
+	require.Contains(t, string(out), "func WithErrorHandler(handler ErrorHandler) CoordinatorOption")
+	require.Contains(t, string(out), "if err := ResolveForPost(ctx, []*Post{result}, c.resolver, c.maxConcurrency); err != nil")
+	require.Contains(t, string(out), "c.errorHandler.HandleError(w, r, err)")
+	require.Regexp(t, regexp.MustCompile("Body\\s+string\\s+`json:\"body\"`"), string(out))
+	require.Regexp(t, regexp.MustCompile("Draft\\s+\\*bool\\s+`json:\"draft,omitempty\"`"), string(out))
 
 	// type tests
 	require.Regexp(t, regexp.MustCompile("ID\\s+int64\\s+`json:\"id\"`"), string(out))
 	require.Regexp(t, regexp.MustCompile("Comments\\s+\\[\\]\\*Comment\\s+`json:\"comments\" resolver:\"ResolvePostComments\""), string(out))
 
+	// nested resolution tests
+	require.Contains(t, string(out), "return resolveLevels(ctx, &resolutionLevel{Post: records}, resolver, maxConcurrency)")
+	require.Contains(t, string(out), "func resolveCommentFields(records []*Comment, resolver Resolver, group *resolverGroup, next *resolutionLevel)")
+	require.Contains(t, string(out), "next.addComment(val...)")
+	require.NotContains(t, string(out), "next.addUser")
+
+	// context tests
+	require.Contains(t, string(out), "ctx := context.WithValue(r.Context(), requestContextKey{}, r)")
+	require.Contains(t, string(out), "func RequestFromContext(ctx context.Context) (*http.Request, bool)")
+
+	// concurrency tests
+	require.Contains(t, string(out), "func WithMaxConcurrency(maxConcurrency int) CoordinatorOption")
+	require.Contains(t, string(out), "group.Go(func(ctx context.Context) error {")
+
 	// resolver tests
 	require.Contains(t, string(out), `package mytypes`)
 	require.Contains(t, string(out), "type Resolver interface")
-	require.Contains(t, string(out), "ResolvePostComments(postIDs []int64) (map[int64][]*Comment, error)")
+	require.Contains(t, string(out), "ResolvePostComments(ctx context.Context, postIDs []int64) (map[int64][]*Comment, error)")
+	require.Contains(t, string(out), "ResolveCommentAuthor(ctx context.Context, commentIDs []string) (map[string]*User, error)")
+	require.Contains(t, string(out), "ids := make([]string, 0, len(records))")
+
+	// schema endpoint tests
+	require.NotRegexp(t, regexp.MustCompile("schemaEndpoint:\\s+true"), string(out))
 
 	fset := token.NewFileSet()
 	_, err = goparser.ParseFile(fset, "", out, goparser.AllErrors)
 	require.NoError(t, err, "Generated code should parse without errors")
+
+	gen.SchemaEndpoint = true
+	writer, err = gen.Coordinator()
+	require.NoError(t, err)
+
+	out, err = io.ReadAll(writer)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile("schemaEndpoint:\\s+true,"), string(out))
+	require.Contains(t, string(out), "// provided resolver and controller. The schema endpoint\n// is always served.\n")
+}
+
+// e2eSchema is generated into the checked in overtime package, whose
+// generated.go doubles as its golden file.
+const e2eSchema = "e2e.yaml"
+
+// TestGolden generates each schema in testdata, and the end-to-end schema,
+// several times, ensuring the output is identical across runs and matches the
+// checked in golden file. Run with -update to regenerate the golden files.
+func TestGolden(t *testing.T) {
+	schemaPaths, err := filepath.Glob("testdata/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, schemaPaths)
+
+	for _, schemaPath := range append(schemaPaths, e2eSchema) {
+		name := strings.TrimSuffix(filepath.Base(schemaPath), ".yaml")
+		goldenPath, packageName := goldenFile(schemaPath)
+
+		t.Run(name, func(t *testing.T) {
+			out := generateFile(t, schemaPath, packageName)
+			for i := 0; i < 10; i++ {
+				require.Equal(t, out, generateFile(t, schemaPath, packageName), "Generated code should be identical across runs")
+			}
+
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, []byte(out), 0o644))
+			}
+
+			golden, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			require.Equal(t, string(golden), out)
+		})
+	}
+}
+
+// TestGoldenDSL ensures schemas written in the DSL generate the same code as
+// their YAML equivalent.
+func TestGoldenDSL(t *testing.T) {
+	goldenPath, packageName := goldenFile(e2eSchema)
+	golden, err := os.ReadFile(goldenPath)
+	require.NoError(t, err)
+
+	require.Equal(t, string(golden), generateFile(t, "testdata/blog.overtime", packageName))
+}
+
+// goldenFile returns the path of the golden file of a schema and the name of
+// the package it's generated into.
+func goldenFile(schemaPath string) (string, string) {
+	if schemaPath == e2eSchema {
+		return filepath.Join("overtime", "generated.go"), "overtime"
+	}
+
+	return strings.TrimSuffix(schemaPath, ".yaml") + ".golden", "golden"
+}
+
+func generateFile(t *testing.T, schemaPath string, packageName string) string {
+	t.Helper()
+
+	schema, err := parser.ParseFile(schemaPath)
+	require.NoError(t, err)
+
+	gen := NewGo(schema)
+	gen.PackageName = packageName
+
+	r, err := gen.Coordinator()
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(out)
 }
 
+// Test_EndToEnd runs `overtime generate` for the e2e schema in a new module,
+// ensuring the generated package and the implementation bootstrapped for it
+// compile. It runs outside of the repository so the checked in package is
+// left untouched.
 func Test_EndToEnd(t *testing.T) {
-	cmd := exec.Command("go", "run", "../main.go", "generate", "./e2e.yaml", "-d", "generator/test")
-	cmd.Stderr = os.Stdout
-	cmd.Stdout = os.Stdout
-	err := cmd.Run()
+	schemaPath, err := filepath.Abs(e2eSchema)
+	require.NoError(t, err)
+
+	bin := filepath.Join(t.TempDir(), "overtime")
+	build := exec.Command("go", "build", "-o", bin, "..")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/e2e\n\ngo 1.22\n"), 0o644))
+
+	cmd := exec.Command(bin, "generate", "--directory", "internal", schemaPath)
+	cmd.Dir = dir
+	out, err = cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	pkg := filepath.Join(dir, "internal", DefaultPackageName)
+	require.FileExists(t, filepath.Join(pkg, "generated.go"))
+	require.FileExists(t, filepath.Join(pkg, "impl.go"))
+	requireVet(t, pkg)
+}
+
+func TestCodeGenReportsInvalidSource(t *testing.T) {
+	schema, err := parser.Parse(strings.NewReader(`
+types:
+    Post:
+        fields:
+            id: int64
+endpoints:
+    'GET /posts/"oops':
+        name: ListPosts
+        response:
+            body: "[]Post"`))
 	require.NoError(t, err)
+
+	_, err = NewGo(schema).Coordinator()
+
+	var sourceErr *SourceError
+	require.ErrorAs(t, err, &sourceErr)
+	require.Equal(t, `endpoint GET /posts/"oops (7:5)`, sourceErr.Origin)
+	require.Equal(t, `c.mux.HandleFunc("GET /posts/"oops", func(w http.ResponseWriter, r *http.Request) {`, sourceErr.Source)
+	require.Contains(t, err.Error(), `Generated invalid Go code for endpoint GET /posts/"oops (7:5): `)
 }
@@ -17,10 +17,14 @@ func (ce *Endpoint) Method() string {
 }
 
 func (ce *Endpoint) MethodName() string {
-	return capitalize(ce.endpoint.Name)
+	return parser.Capitalize(ce.endpoint.Name)
 }
 
 func (ce *Endpoint) ReturnValue() string {
+	if !ce.HasBody() {
+		return ""
+	}
+
 	if strings.HasPrefix(ce.endpoint.Returns, "[]") {
 		return "[]" + "*" + strings.TrimPrefix(ce.endpoint.Returns, "[]")
 	} else {
@@ -28,6 +32,111 @@ func (ce *Endpoint) ReturnValue() string {
 	}
 }
 
+// HasBody returns true if the successful response of the endpoint has a body.
+func (ce *Endpoint) HasBody() bool {
+	return ce.endpoint.Returns != ""
+}
+
+// Results returns the results of the controller method.
+func (ce *Endpoint) Results() string {
+	if !ce.HasBody() {
+		return "error"
+	}
+
+	return fmt.Sprintf("(%s, error)", ce.ReturnValue())
+}
+
+// ControllerCall returns the statement calling the controller method.
+func (ce *Endpoint) ControllerCall() string {
+	args := "ctx"
+	if ce.HasInput() {
+		args += ", input"
+	}
+
+	switch {
+	case ce.HasBody():
+		return fmt.Sprintf("result, err := c.controller.%s(%s)", ce.MethodName(), args)
+	case ce.HasInput():
+		return fmt.Sprintf("err = c.controller.%s(%s)", ce.MethodName(), args)
+	default:
+		return fmt.Sprintf("err := c.controller.%s(%s)", ce.MethodName(), args)
+	}
+}
+
+// StatusCode returns the status of the successful response.
+func (ce *Endpoint) StatusCode() string {
+	return statusCode(ce.endpoint.Status)
+}
+
+// ErrorResponses returns the declared non-successful responses that have a
+// body.
+func (ce *Endpoint) ErrorResponses() []GoResponse {
+	responses := make([]GoResponse, 0, len(ce.endpoint.Responses))
+	for _, response := range ce.endpoint.Responses {
+		if response.IsSuccess() || response.Body == "" {
+			continue
+		}
+
+		responses = append(responses, GoResponse{response: response})
+	}
+
+	return responses
+}
+
+// ResponsesComment documents every response the endpoint can return.
+func (ce *Endpoint) ResponsesComment() string {
+	responses := make([]string, len(ce.endpoint.Responses))
+	for i, response := range ce.endpoint.Responses {
+		responses[i] = fmt.Sprint(response.Status)
+		if response.Body != "" {
+			responses[i] += " " + response.Body
+		}
+	}
+
+	return formatComment("Responds with " + strings.Join(responses, ", "))
+}
+
+// InputName returns the name of the struct holding the decoded arguments of
+// the endpoint.
+func (ce *Endpoint) InputName() string {
+	return ce.MethodName() + "Input"
+}
+
+// HasInput returns true if the endpoint declares any arguments.
+func (ce *Endpoint) HasInput() bool {
+	return len(ce.endpoint.Args) > 0
+}
+
+// HasBodyInput returns true if any of the endpoint arguments are read from
+// the request body.
+func (ce *Endpoint) HasBodyInput() bool {
+	for _, arg := range ce.endpoint.Args {
+		if arg.Source == parser.InputBody {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ce *Endpoint) Inputs() []GoInput {
+	inputs := make([]GoInput, 0, len(ce.endpoint.Args))
+	for _, arg := range parser.SortedFields(ce.endpoint.Args) {
+		inputs = append(inputs, GoInput{parserField: arg, schema: ce.schema})
+	}
+
+	return inputs
+}
+
+// Arguments returns the arguments passed to the controller method.
+func (ce *Endpoint) Arguments() string {
+	if !ce.HasInput() {
+		return "ctx context.Context"
+	}
+
+	return "ctx context.Context, input *" + ce.InputName()
+}
+
 func (ce *Endpoint) Path() string {
 	parts := strings.Split(ce.endpoint.Path, "/")
 	formattedParts := make([]string, len(parts))
@@ -43,29 +152,61 @@ func (ce *Endpoint) Path() string {
 	return strings.Join(formattedParts, "/")
 }
 
+// Origin describes the endpoint in errors about the generated code.
+func (ce *Endpoint) Origin() string {
+	return origin("endpoint "+ce.endpoint.Method+" "+ce.endpoint.Path, ce.endpoint.Pos)
+}
+
 func (ce *Endpoint) Comment() string {
 	return formatComment(ce.endpoint.DocComment)
 }
 
 func (ce *Endpoint) ResolverMethod() string {
-	goType := GoType{parserType: ce.schema.Types[rootType(ce.endpoint.Returns)]}
+	if !ce.HasBody() {
+		return ""
+	}
+
+	goType := GoType{parserType: ce.schema.Types[rootType(ce.endpoint.Returns)], schema: ce.schema}
 	if !goType.NeedsResolver() {
 		return ""
 	}
 
 	if strings.HasPrefix(ce.endpoint.Returns, "[]") {
-		return fmt.Sprintf("ResolveFor%s(result, c.resolver)", capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")))
+		return fmt.Sprintf("ResolveFor%s(ctx, result, c.resolver, c.maxConcurrency)", parser.Capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")))
 	}
 
 	return fmt.Sprintf(
-		"ResolveFor%s([]*%s{result}, c.resolver)",
-		capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")),
-		capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")),
+		"ResolveFor%s(ctx, []*%s{result}, c.resolver, c.maxConcurrency)",
+		parser.Capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")),
+		parser.Capitalize(strings.TrimPrefix(ce.endpoint.Returns, "[]")),
 	)
 }
 
+// GoResponse represents a declared error response of an endpoint. The body
+// type is returned as an error by the controller.
+type GoResponse struct {
+	response parser.Response
+}
+
+func (gr *GoResponse) StatusCode() string {
+	return statusCode(gr.response.Status)
+}
+
+func (gr *GoResponse) TypeName() string {
+	return parser.Capitalize(gr.response.Body)
+}
+
+// VarName returns the name of the variable the error is extracted into.
+func (gr *GoResponse) VarName() string {
+	return uncapitalize(gr.TypeName()) + "Response"
+}
+
 type GoType struct {
 	parserType *parser.Type
+	schema     *parser.Schema
+	// isErrorResponse is true when the type is the body of an error
+	// response and has to implement error.
+	isErrorResponse bool
 }
 
 func (gt *GoType) Name() string {
@@ -73,38 +214,50 @@ func (gt *GoType) Name() string {
 		return "ID"
 	}
 
-	return capitalize(gt.parserType.Name)
+	return parser.Capitalize(gt.parserType.Name)
 }
 
-func (gt *GoType) MapType() string {
-	if strings.HasPrefix(gt.Name(), "[]") {
-		return "map[int64][]*" + capitalize(strings.TrimPrefix(gt.Name(), "[]"))
-	} else {
-		return "map[int64]*" + capitalize(gt.Name())
-	}
+// Origin describes the type in errors about the generated code.
+func (gt *GoType) Origin() string {
+	return origin("type "+gt.parserType.Name, gt.parserType.Pos)
 }
 
 func (gt *GoType) Fields() []GoField {
 	fields := make([]GoField, 0, len(gt.parserType.Fields))
 
-	for _, field := range gt.parserType.Fields {
+	for _, field := range parser.SortedFields(gt.parserType.Fields) {
 		fields = append(fields, GoField{parserField: field, parentType: gt})
 	}
 
 	return fields
 }
 
+// IDType returns the Go type of the id field, used to key resolver results.
 func (gt *GoType) IDType() string {
-	return gt.parserType.Fields["id"].Type
+	return goType(gt.schema, gt.parserType.Fields["id"].Type)
 }
 
 func (gt *GoType) Comment() string {
 	return formatComment(gt.parserType.DocComment)
 }
 
+func (gt *GoType) IsErrorResponse() bool {
+	return gt.isErrorResponse
+}
+
+// ErrorMessage returns the expression used as the message of an error
+// response, using its message field when present.
+func (gt *GoType) ErrorMessage() string {
+	if field, ok := gt.parserType.Fields["message"]; ok && field.Type == "string" {
+		return "e.Message"
+	}
+
+	return fmt.Sprintf("%q", gt.Name())
+}
+
 func (gt *GoType) NeedsResolver() bool {
 	for _, field := range gt.Fields() {
-		if !builtins[field.normalizedType()] {
+		if !field.IsScalar() {
 			return true
 		}
 	}
@@ -115,7 +268,7 @@ func (gt *GoType) NeedsResolver() bool {
 func (gt *GoType) Resolvers() []GoResolver {
 	resolvers := make([]GoResolver, 0)
 	for _, field := range gt.Fields() {
-		if builtins[field.normalizedType()] {
+		if field.IsScalar() {
 			continue
 		}
 
@@ -140,21 +293,81 @@ func (gr *GoResolver) MethodName() string {
 
 func (gr *GoResolver) Arguments() string {
 	return fmt.Sprintf(
-		"%sIDs []%s",
+		"ctx context.Context, %sIDs []%s",
 		uncapitalize(gr.goType.Name()),
 		gr.goType.IDType(),
 	)
 }
 
+// Origin describes the field populated by the resolver in errors about the
+// generated code.
+func (gr *GoResolver) Origin() string {
+	return gr.field.Origin()
+}
+
 func (gr *GoResolver) Comment() string {
 	return formatComment(fmt.Sprintf(`Populates the %s field for the %s type`, gr.field.Name(), gr.goType.Name()))
 }
 
 func (gr *GoResolver) ReturnType() string {
-	if strings.HasPrefix(gr.field.Type(), "[]") {
-		return "map[int64][]" + capitalize(strings.TrimPrefix(gr.field.Type(), "[]"))
-	} else {
-		return "map[int64]" + capitalize(gr.field.Type())
+	return fmt.Sprintf("map[%s]%s", gr.goType.IDType(), gr.field.Type())
+}
+
+// GoEnum represents an enum of the schema, generated as a string type with a
+// constant for every value.
+type GoEnum struct {
+	parserEnum *parser.Enum
+}
+
+// GoEnumValue is a single value of an enum and the constant declared for it.
+type GoEnumValue struct {
+	ConstantName string
+	Value        string
+}
+
+func (ge *GoEnum) Name() string {
+	return parser.Capitalize(ge.parserEnum.Name)
+}
+
+// Origin describes the enum in errors about the generated code.
+func (ge *GoEnum) Origin() string {
+	return origin("enum "+ge.parserEnum.Name, ge.parserEnum.Pos)
+}
+
+func (ge *GoEnum) Comment() string {
+	return formatComment(ge.parserEnum.DocComment)
+}
+
+// Values returns the values of the enum in declaration order. Constants are
+// named after the enum and the value, e.g. PostStateInReview for in_review.
+func (ge *GoEnum) Values() []GoEnumValue {
+	values := make([]GoEnumValue, len(ge.parserEnum.Values))
+	for i, value := range ge.parserEnum.Values {
+		name := strings.Builder{}
+		name.WriteString(ge.Name())
+
+		for _, part := range strings.Split(value, "_") {
+			name.WriteString(parser.Capitalize(part))
+		}
+
+		values[i] = GoEnumValue{ConstantName: name.String(), Value: value}
+	}
+
+	return values
+}
+
+// Description describes the accepted values in validation messages, e.g.
+// `one of draft, published, or archived`.
+func (ge *GoEnum) Description() string {
+	values := ge.parserEnum.Values
+
+	switch len(values) {
+	case 1:
+		return values[0]
+	case 2:
+		return fmt.Sprintf("one of %s or %s", values[0], values[1])
+	default:
+		return fmt.Sprintf("one of %s, or %s", strings.Join(values[:len(values)-1], ", "), values[len(values)-1])
 	}
 }
 
@@ -164,11 +377,12 @@ type GoField struct {
 }
 
 func (gf *GoField) Name() string {
-	if gf.parserField.Name == "id" {
-		return "ID"
-	}
+	return parser.FieldName(gf.parserField.Name)
+}
 
-	return capitalize(strings.TrimSuffix(gf.parserField.Name, "?"))
+// Origin describes the field in errors about the generated code.
+func (gf *GoField) Origin() string {
+	return origin(fmt.Sprintf("field `%s` of type %s", gf.parserField.Name, gf.parentType.parserType.Name), gf.parserField.Pos)
 }
 
 func (gf *GoField) Comment() string {
@@ -176,18 +390,33 @@ func (gf *GoField) Comment() string {
 }
 
 func (gf *GoField) Type() string {
-	if builtins[gf.normalizedType()] {
-		return gf.parserField.Type
+	return goType(gf.parentType.schema, gf.parserField.Type)
+}
+
+// NextLevel returns the statement adding the resolved value to the next level
+// of resolution when its type has resolver fields of its own.
+func (gf *GoField) NextLevel() string {
+	t, ok := gf.parentType.schema.Types[gf.normalizedType()]
+	if !ok {
+		return ""
 	}
-	if strings.HasPrefix(gf.parserField.Type, "[]") && !builtins[gf.normalizedType()] {
-		return "[]" + "*" + strings.TrimPrefix(gf.parserField.Type, "[]")
-	} else {
-		return "*" + gf.parserField.Type
+
+	fieldType := GoType{parserType: t, schema: gf.parentType.schema}
+	if !fieldType.NeedsResolver() {
+		return ""
+	}
+
+	if strings.HasPrefix(gf.parserField.Type, "[]") {
+		return fmt.Sprintf("next.add%s(val...)", fieldType.Name())
 	}
+
+	return fmt.Sprintf("next.add%s(val)", fieldType.Name())
 }
 
-func (gf *GoField) IsBuiltin() bool {
-	return builtins[gf.normalizedType()]
+// IsScalar returns true if the field is a builtin or an enum, or a list of
+// one, so it isn't populated by a resolver.
+func (gf *GoField) IsScalar() bool {
+	return gf.parentType.schema.IsScalar(gf.normalizedType())
 }
 
 func (gf *GoField) normalizedType() string {
@@ -202,7 +431,7 @@ func (gf *GoField) Tags() string {
 		omitEmpty = ",omitempty"
 	}
 	tag.Write([]byte(fmt.Sprintf("json:\"%s%s\"", gf.parserField.Name, omitEmpty)))
-	if !builtins[gf.normalizedType()] {
+	if !gf.IsScalar() {
 		tag.Write([]byte(fmt.Sprintf(" resolver:\"%s\"", gf.ResolverMethodName())))
 		// TODO backfill
 		// resolvers[resolverName] = field
@@ -221,9 +450,144 @@ func (gf *GoField) ResolverMethodName() string {
 }
 
 func (gf *GoField) IsOptional() bool {
-	return strings.HasSuffix(gf.parserField.Name, "?")
+	return gf.parserField.IsOptional
+}
+
+// GoInput represents a single argument of an endpoint, decoded from the path,
+// query string, or body of the request.
+type GoInput struct {
+	parserField parser.Field
+	schema      *parser.Schema
+}
+
+func (gi *GoInput) Name() string {
+	return parser.FieldName(gi.parserField.Name)
+}
+
+// Origin describes the argument in errors about the generated code.
+func (gi *GoInput) Origin() string {
+	return origin(fmt.Sprintf("input `%s`", gi.parserField.Name), gi.parserField.Pos)
+}
+
+// ParamName returns the name of the argument as it appears in the request.
+func (gi *GoInput) ParamName() string {
+	return gi.parserField.Name
+}
+
+// Type returns the Go type of the struct field holding the argument. Optional
+// scalar arguments are pointers so that absent values can be told apart from
+// zero values.
+func (gi *GoInput) Type() string {
+	if gi.IsOptional() && gi.schema.IsScalar(gi.parserField.Type) {
+		return "*" + gi.ElemType()
+	}
+
+	return gi.ElemType()
+}
+
+// ElemType returns the Go type of the argument value.
+func (gi *GoInput) ElemType() string {
+	return goType(gi.schema, gi.parserField.Type)
+}
+
+func (gi *GoInput) Tags() string {
+	if !gi.IsBody() {
+		return "`json:\"-\"`"
+	}
+
+	omitEmpty := ""
+	if gi.IsOptional() {
+		omitEmpty = ",omitempty"
+	}
+
+	return fmt.Sprintf("`json:\"%s%s\"`", gi.parserField.Name, omitEmpty)
+}
+
+// Decoder returns the expression used to decode and validate the argument
+// from the request.
+func (gi *GoInput) Decoder() string {
+	name := gi.parserField.Name
+	elemType := gi.ElemType()
+	optionalScalar := gi.IsOptional() && gi.schema.IsScalar(gi.parserField.Type)
+
+	switch {
+	case gi.IsPath():
+		return fmt.Sprintf("pathParam[%s](d, %q)", elemType, name)
+	case gi.IsBody() && optionalScalar:
+		return fmt.Sprintf("optionalBodyField[%s](d, %q)", elemType, name)
+	case gi.IsBody():
+		return fmt.Sprintf("bodyField[%s](d, %q, %t)", elemType, name, !gi.IsOptional())
+	case optionalScalar:
+		return fmt.Sprintf("optionalQueryParam[%s](d, %q)", elemType, name)
+	default:
+		return fmt.Sprintf("queryParam[%s](d, %q)", elemType, name)
+	}
+}
+
+func (gi *GoInput) IsOptional() bool {
+	return gi.parserField.IsOptional
+}
+
+func (gi *GoInput) IsPath() bool {
+	return gi.parserField.Source == parser.InputPath
+}
+
+func (gi *GoInput) IsBody() bool {
+	return gi.parserField.Source == parser.InputBody
+}
+
+func (gi *GoInput) Source() string {
+	return gi.parserField.Source.String()
+}
+
+// statusCode returns the net/http constant for a status, falling back to the
+// numeric value for statuses without one.
+func statusCode(status int) string {
+	if name, ok := statusNames[status]; ok {
+		return "http.Status" + name
+	}
+
+	return fmt.Sprint(status)
+}
+
+var statusNames = map[int]string{
+	200: "OK",
+	201: "Created",
+	202: "Accepted",
+	204: "NoContent",
+	400: "BadRequest",
+	401: "Unauthorized",
+	403: "Forbidden",
+	404: "NotFound",
+	409: "Conflict",
+	410: "Gone",
+	422: "UnprocessableEntity",
+	429: "TooManyRequests",
+	500: "InternalServerError",
+	503: "ServiceUnavailable",
 }
 
 func rootType(t string) string {
 	return strings.TrimPrefix(t, "[]")
 }
+
+// goType returns the Go type for a schema type. Object types are pointers so
+// that they can be populated by resolvers.
+func goType(schema *parser.Schema, t string) string {
+	normalized := rootType(t)
+	prefix := strings.TrimSuffix(t, normalized)
+
+	if _, ok := schema.Enums[normalized]; ok {
+		return prefix + parser.Capitalize(normalized)
+	}
+
+	if builtins[normalized] {
+		if normalized == "float" {
+			normalized = "float64"
+		}
+
+		return prefix + normalized
+	}
+
+	return prefix + "*" + normalized
+}
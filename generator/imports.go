@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// pruneImports removes the imports that src does not reference, so templates
+// can import every package they might use without tracking which sections
+// were rendered. It's only used on fully generated files, never on
+// hand-written ones. Blank and dot imports, and imports whose package name
+// isn't known, are always kept.
+func pruneImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.ParseComments|goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	// unused holds the byte ranges to remove from src.
+	unused := make([][2]int, 0)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		removed := 0
+		for _, spec := range gen.Specs {
+			name, ok := importName(spec.(*ast.ImportSpec))
+			if !ok || name == "_" || name == "." || used[name] {
+				continue
+			}
+
+			removed++
+			if gen.Lparen.IsValid() {
+				unused = append(unused, lineRange(src, fset.Position(spec.Pos()).Offset, fset.Position(spec.End()).Offset))
+			}
+		}
+
+		if removed > 0 && removed == len(gen.Specs) {
+			// Drop the whole declaration instead of leaving an empty import.
+			if gen.Lparen.IsValid() {
+				unused = unused[:len(unused)-removed]
+			}
+			unused = append(unused, lineRange(src, fset.Position(gen.Pos()).Offset, fset.Position(gen.End()).Offset))
+		}
+	}
+
+	if len(unused) == 0 {
+		return src, nil
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i][0] > unused[j][0]
+	})
+
+	pruned := bytes.Clone(src)
+	for _, r := range unused {
+		pruned = append(pruned[:r[0]], pruned[r[1]:]...)
+	}
+
+	return pruned, nil
+}
+
+// importName returns the name an import is referenced by in the file, or
+// false when it can't be known without loading the package. Only standard
+// library packages are assumed to be named after the last element of their
+// path, skipping major version suffixes, since other packages often aren't,
+// e.g. gopkg.in/yaml.v3 is named yaml.
+func importName(spec *ast.ImportSpec) (string, bool) {
+	if spec.Name != nil {
+		return spec.Name.Name, true
+	}
+
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+		return "", false
+	}
+
+	name := path.Base(importPath)
+	if majorVersionRegex.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name, true
+}
+
+// lineRange expands the range between start and end to the full lines it
+// covers, including the trailing newline.
+func lineRange(src []byte, start int, end int) [2]int {
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+
+	if end < len(src) {
+		end++
+	}
+
+	return [2]int{start, end}
+}
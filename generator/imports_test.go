@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"go/format"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneImportsRemovesUnusedImports(t *testing.T) {
+	src := `package example
+
+import (
+	"context"
+	"fmt"
+	"strings" // only used by inputs
+	yaml "gopkg.in/yaml.v3"
+	cli "github.com/urfave/cli/v2"
+	_ "embed"
+)
+
+import "sync"
+
+func hello(ctx context.Context) string {
+	var _ *cli.App
+	return fmt.Sprint("hello")
+}
+`
+
+	pruned, err := pruneImports([]byte(src))
+	require.NoError(t, err)
+
+	formatted, err := format.Source(pruned)
+	require.NoError(t, err)
+
+	require.Equal(t, `package example
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	cli "github.com/urfave/cli/v2"
+)
+
+func hello(ctx context.Context) string {
+	var _ *cli.App
+	return fmt.Sprint("hello")
+}
+`, string(formatted))
+}
+
+func TestPruneImportsKeepsUsedImports(t *testing.T) {
+	src := "package example\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint\n"
+
+	pruned, err := pruneImports([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, src, string(pruned))
+}
+
+func TestPruneImportsKeepsImportsWithUnknownNames(t *testing.T) {
+	src := `package example
+
+import (
+	"math/rand/v2"
+	"net/http"
+
+	"github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = sqlite3.ErrNo
+var _ = yaml.Marshal
+`
+
+	pruned, err := pruneImports([]byte(src))
+	require.NoError(t, err)
+
+	formatted, err := format.Source(pruned)
+	require.NoError(t, err)
+
+	require.Equal(t, `package example
+
+import (
+	"github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = sqlite3.ErrNo
+var _ = yaml.Marshal
+`, string(formatted))
+}
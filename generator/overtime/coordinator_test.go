@@ -0,0 +1,384 @@
+package overtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinatorStopsResolvingRecordsReferringBackToEachOther(t *testing.T) {
+	resolver := &recordingResolver{}
+	res := serve(t, NewCoordinator(resolver, &RootController{}), http.MethodGet, "/api/v1/posts", "")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	var posts []*Post
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &posts))
+
+	author := posts[0].Author
+	require.Equal(t, "author 1", author.Name)
+	require.Len(t, author.Posts, 1)
+	require.Nil(t, author.Posts[0].Author, "Post 1 was already resolved and should not be resolved again")
+	require.Equal(t, []string{
+		"ResolveCommentAuthor [c1 c2]",
+		"ResolvePostAuthor [1]",
+		"ResolvePostComments [1]",
+		"ResolveUserPosts [1]",
+		"ResolveUserPosts [2]",
+	}, resolver.Calls())
+}
+
+func TestCoordinatorBatchesResolversPerLevel(t *testing.T) {
+	resolver := &recordingResolver{}
+	res := serve(t, NewCoordinator(resolver, &listController{}), http.MethodGet, "/api/v1/posts", "")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	// Every resolver is called once per level with the ids of every record
+	// at that level: posts, then their comments and authors, then the
+	// authors of the comments.
+	require.Equal(t, []string{
+		"ResolveCommentAuthor [c1 c2]",
+		"ResolvePostAuthor [1 2 3]",
+		"ResolvePostComments [1 2 3]",
+		"ResolveUserPosts [1]",
+		"ResolveUserPosts [2]",
+	}, resolver.Calls())
+}
+
+func TestCoordinatorLimitsResolverConcurrency(t *testing.T) {
+	for _, limit := range []int{0, 1} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			resolver := &recordingResolver{
+				before: func(ctx context.Context, method string) error {
+					current := running.Add(1)
+					defer running.Add(-1)
+
+					for {
+						previous := maxRunning.Load()
+						if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+							break
+						}
+					}
+
+					time.Sleep(20 * time.Millisecond)
+
+					return nil
+				},
+			}
+
+			res := serve(t, NewCoordinator(resolver, &RootController{}, WithMaxConcurrency(limit)), http.MethodGet, "/api/v1/posts", "")
+			require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+			if limit == 0 {
+				require.Equal(t, int32(2), maxRunning.Load(), "The resolvers of a level should run concurrently")
+			} else {
+				require.Equal(t, int32(limit), maxRunning.Load())
+			}
+		})
+	}
+}
+
+func TestCoordinatorReturnsEveryResolverError(t *testing.T) {
+	// Both resolvers of the first level wait for each other, so they both
+	// fail instead of the second being canceled by the first.
+	var started sync.WaitGroup
+	started.Add(2)
+
+	resolver := &recordingResolver{
+		before: func(ctx context.Context, method string) error {
+			started.Done()
+			started.Wait()
+
+			return errors.New(method + " failed")
+		},
+	}
+
+	var err error
+	res := serve(t, NewCoordinator(resolver, &RootController{}, recordError(&err)), http.MethodGet, "/api/v1/posts", "")
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+
+	require.ErrorContains(t, err, "ResolvePostComments failed")
+	require.ErrorContains(t, err, "ResolvePostAuthor failed")
+	require.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+	require.Len(t, resolver.Calls(), 2, "Nested records should not be resolved after an error")
+}
+
+func TestCoordinatorStopsResolvingWhenTheRequestIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resolver := &recordingResolver{
+		before: func(ctx context.Context, method string) error {
+			cancel()
+			<-ctx.Done()
+
+			return ctx.Err()
+		},
+	}
+
+	var err error
+	c := NewCoordinator(resolver, &RootController{}, recordError(&err))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil).WithContext(ctx)
+		c.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Resolution did not stop after the request was canceled")
+	}
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotContains(t, strings.Join(resolver.Calls(), "\n"), "ResolveCommentAuthor", "Nested records should not be resolved once the request is canceled")
+}
+
+func TestCoordinatorDecodesInputs(t *testing.T) {
+	c := NewCoordinator(&RootResolver{}, &RootController{})
+
+	res := serve(t, c, http.MethodPost, "/api/v1/posts", `{"body": "hello", "draft": true}`)
+	require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+
+	var post Post
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &post))
+	require.Equal(t, int64(2), post.ID)
+	require.Equal(t, "hello", post.Body)
+
+	res = serve(t, c, http.MethodGet, "/api/v1/posts?page=2", "")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+}
+
+func TestCoordinatorRejectsInvalidInputs(t *testing.T) {
+	c := NewCoordinator(&RootResolver{}, &RootController{})
+
+	tests := map[string]struct {
+		method string
+		target string
+		body   string
+		fields string
+	}{
+		"path": {
+			method: http.MethodGet,
+			target: "/api/v1/posts/abc",
+			fields: `[{"field": "postID", "source": "path", "message": "must be an integer"}]`,
+		},
+		"query": {
+			method: http.MethodGet,
+			target: "/api/v1/posts?page=first",
+			fields: `[{"field": "page", "source": "query", "message": "must be an integer"}]`,
+		},
+		"body": {
+			method: http.MethodPost,
+			target: "/api/v1/posts",
+			body:   `{"body": 1, "draft": "yes"}`,
+			fields: `[{"field": "body", "source": "body", "message": "must be a string"}, {"field": "draft", "source": "body", "message": "must be a boolean"}]`,
+		},
+		"body enum list": {
+			method: http.MethodPost,
+			target: "/api/v1/posts",
+			body:   `{"body": "hello", "tags": ["news", "draft"]}`,
+			fields: `[{"field": "tags", "source": "body", "message": "must be a list of values that are each one of news or release"}]`,
+		},
+		"missing body field": {
+			method: http.MethodPost,
+			target: "/api/v1/posts",
+			body:   `{}`,
+			fields: `[{"field": "body", "source": "body", "message": "is required"}]`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := serve(t, c, tc.method, tc.target, tc.body)
+			require.Equal(t, http.StatusBadRequest, res.Code)
+			require.Equal(t, "application/json", res.Header().Get("Content-Type"))
+			require.JSONEq(t, `{"error": {"code": "invalid_input", "message": "The request contains invalid input", "fields": `+tc.fields+`}}`, res.Body.String())
+		})
+	}
+}
+
+func TestCoordinatorRendersErrors(t *testing.T) {
+	c := NewCoordinator(&RootResolver{}, &errorController{})
+
+	res := serve(t, c, http.MethodGet, "/api/v1/comments/c1", "")
+	require.Equal(t, http.StatusNotFound, res.Code)
+	require.JSONEq(t, `{"error": {"code": "not_found", "message": "Comment c1 does not exist"}}`, res.Body.String())
+
+	res = serve(t, c, http.MethodGet, "/api/v1/posts", "")
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	require.JSONEq(t, `{"error": {"code": "internal_error", "message": "Internal server error"}}`, res.Body.String())
+
+	// Declared error responses use the type as the body.
+	res = serve(t, c, http.MethodGet, "/api/v1/posts/2", "")
+	require.Equal(t, http.StatusNotFound, res.Code)
+	require.JSONEq(t, `{"message": "post not found"}`, res.Body.String())
+
+	res = serve(t, c, http.MethodDelete, "/api/v1/posts/1", "")
+	require.Equal(t, http.StatusNoContent, res.Code)
+	require.Empty(t, res.Body.String())
+
+	handled := NewCoordinator(&RootResolver{}, &errorController{}, WithErrorHandler(ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, err.Error(), http.StatusTeapot)
+	})))
+
+	res = serve(t, handled, http.MethodGet, "/api/v1/comments/c1", "")
+	require.Equal(t, http.StatusTeapot, res.Code)
+	require.Equal(t, "404 not_found: Comment c1 does not exist\n", res.Body.String())
+}
+
+func TestCoordinatorServesSchema(t *testing.T) {
+	generated, err := os.ReadFile("generated.go")
+	require.NoError(t, err)
+	require.Contains(t, string(generated), "\n// Schema version: "+SchemaVersion+"\n")
+	require.Equal(t, "0.1.0", SchemaVersion)
+
+	res := serve(t, NewCoordinator(&RootResolver{}, &RootController{}), http.MethodGet, "/_overtime/schema", "")
+	require.Equal(t, http.StatusNotFound, res.Code, "The schema is only served when enabled")
+
+	res = serve(t, NewCoordinator(&RootResolver{}, &RootController{}, WithSchemaEndpoint()), http.MethodGet, "/_overtime/schema", "")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.JSONEq(t, `{
+		"version": "0.1.0",
+		"endpoints": [
+			{"name": "GetCommentByID", "method": "GET", "path": "/api/v1/comments/{commentID}"},
+			{"name": "ListPosts", "method": "GET", "path": "/api/v1/posts"},
+			{"name": "CreatePost", "method": "POST", "path": "/api/v1/posts"},
+			{"name": "GetPostByID", "method": "GET", "path": "/api/v1/posts/{postID}"},
+			{"name": "DeletePost", "method": "DELETE", "path": "/api/v1/posts/{postID}"}
+		]
+	}`, res.Body.String())
+}
+
+// errorController wraps RootController, failing the endpoints used to test
+// error handling.
+type errorController struct {
+	RootController
+}
+
+func (c *errorController) GetCommentByID(ctx context.Context, input *GetCommentByIDInput) (*Comment, error) {
+	return nil, NotFound(fmt.Sprintf("Comment %s does not exist", input.CommentID))
+}
+
+func (c *errorController) ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error) {
+	return nil, errors.New("database unavailable")
+}
+
+// recordingResolver wraps RootResolver, recording every resolver called and
+// the ids it was called with.
+type recordingResolver struct {
+	RootResolver
+
+	// before is called before every resolver with its name, returning an
+	// error to fail the call.
+	before func(ctx context.Context, method string) error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingResolver) record(ctx context.Context, method string, ids any) error {
+	r.mu.Lock()
+	r.calls = append(r.calls, fmt.Sprintf("%s %v", method, ids))
+	r.mu.Unlock()
+
+	if r.before == nil {
+		return nil
+	}
+
+	return r.before(ctx, method)
+}
+
+// Calls returns the recorded calls, sorted since the resolvers of a level run
+// concurrently.
+func (r *recordingResolver) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := append([]string(nil), r.calls...)
+	sort.Strings(calls)
+
+	return calls
+}
+
+func (r *recordingResolver) ResolvePostComments(ctx context.Context, ids []int64) (map[int64][]*Comment, error) {
+	if err := r.record(ctx, "ResolvePostComments", ids); err != nil {
+		return nil, err
+	}
+
+	return r.RootResolver.ResolvePostComments(ctx, ids)
+}
+
+func (r *recordingResolver) ResolvePostAuthor(ctx context.Context, ids []int64) (map[int64]*User, error) {
+	if err := r.record(ctx, "ResolvePostAuthor", ids); err != nil {
+		return nil, err
+	}
+
+	return r.RootResolver.ResolvePostAuthor(ctx, ids)
+}
+
+func (r *recordingResolver) ResolveCommentAuthor(ctx context.Context, ids []string) (map[string]*User, error) {
+	if err := r.record(ctx, "ResolveCommentAuthor", ids); err != nil {
+		return nil, err
+	}
+
+	return r.RootResolver.ResolveCommentAuthor(ctx, ids)
+}
+
+func (r *recordingResolver) ResolveUserPosts(ctx context.Context, ids []int64) (map[int64][]*Post, error) {
+	if err := r.record(ctx, "ResolveUserPosts", ids); err != nil {
+		return nil, err
+	}
+
+	return r.RootResolver.ResolveUserPosts(ctx, ids)
+}
+
+// listController wraps RootController, listing several posts so resolvers are
+// called with more than one id.
+type listController struct {
+	RootController
+}
+
+func (c *listController) ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error) {
+	return []*Post{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+}
+
+// recordError returns an ErrorHandler storing the error it handles in err.
+func recordError(err *error) CoordinatorOption {
+	return WithErrorHandler(ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, handled error) {
+		*err = handled
+		DefaultErrorHandler{}.HandleError(w, r, handled)
+	}))
+}
+
+// serve sends a request to the coordinator, returning the recorded response.
+func serve(t *testing.T, handler http.Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, target, r)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	return res
+}
@@ -0,0 +1,1123 @@
+// Code generated by github.com/blakewilliams/overtime DO NOT EDIT
+// Schema version: 0.1.0
+
+package overtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaVersion is the version of the schema format the code was generated
+// from, it is empty when the schema doesn't declare one.
+const SchemaVersion = "0.1.0"
+
+// Coordinator is the main entrypoint for the server and is responsible for
+// routing requests to the correct endpoint and invoking the correct method
+// on the controller. It also handles serializing the response and calling
+// resolver methods to efficiently fetch related data.
+type Coordinator struct {
+	mux            http.ServeMux
+	resolver       Resolver
+	controller     Controller
+	errorHandler   ErrorHandler
+	maxConcurrency int
+	schemaEndpoint bool
+}
+
+// CoordinatorOption configures optional behavior of a Coordinator.
+type CoordinatorOption func(*Coordinator)
+
+// WithErrorHandler sets the ErrorHandler used to render errors returned
+// while serving a request. DefaultErrorHandler is used when not set.
+func WithErrorHandler(handler ErrorHandler) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.errorHandler = handler
+	}
+}
+
+// WithMaxConcurrency limits how many resolvers run concurrently while
+// resolving a single level of a response. Resolvers are not limited when
+// not set.
+func WithMaxConcurrency(maxConcurrency int) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.maxConcurrency = maxConcurrency
+	}
+}
+
+// WithSchemaEndpoint serves a description of the schema, including its
+// version and endpoints, as JSON from GET /_overtime/schema.
+func WithSchemaEndpoint() CoordinatorOption {
+	return func(c *Coordinator) {
+		c.schemaEndpoint = true
+	}
+}
+
+// NewCoordinator returns a new Coordinator that passes requests to the
+// provided resolver and controller.
+func NewCoordinator(resolver Resolver, controller Controller, opts ...CoordinatorOption) *Coordinator {
+	c := &Coordinator{
+		mux:          http.ServeMux{},
+		resolver:     resolver,
+		controller:   controller,
+		errorHandler: DefaultErrorHandler{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.mux.HandleFunc("GET /api/v1/comments/{commentID}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+		input, err := decodeGetCommentByIDInput(r)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		result, err := c.controller.GetCommentByID(ctx, input)
+		if err != nil {
+
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		if err := ResolveForComment(ctx, []*Comment{result}, c.resolver, c.maxConcurrency); err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		c.writeResponse(w, r, http.StatusOK, result)
+	})
+
+	c.mux.HandleFunc("GET /api/v1/posts", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+		input, err := decodeListPostsInput(r)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		result, err := c.controller.ListPosts(ctx, input)
+		if err != nil {
+
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		if err := ResolveForPost(ctx, result, c.resolver, c.maxConcurrency); err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		c.writeResponse(w, r, http.StatusOK, result)
+	})
+
+	c.mux.HandleFunc("POST /api/v1/posts", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+		input, err := decodeCreatePostInput(r)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		result, err := c.controller.CreatePost(ctx, input)
+		if err != nil {
+
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		if err := ResolveForPost(ctx, []*Post{result}, c.resolver, c.maxConcurrency); err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		c.writeResponse(w, r, http.StatusCreated, result)
+	})
+
+	c.mux.HandleFunc("GET /api/v1/posts/{postID}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+		input, err := decodeGetPostByIDInput(r)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		result, err := c.controller.GetPostByID(ctx, input)
+		if err != nil {
+			var notFoundErrorResponse *NotFoundError
+			if errors.As(err, &notFoundErrorResponse) {
+				c.writeResponse(w, r, http.StatusNotFound, notFoundErrorResponse)
+				return
+			}
+
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		if err := ResolveForPost(ctx, []*Post{result}, c.resolver, c.maxConcurrency); err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		c.writeResponse(w, r, http.StatusOK, result)
+	})
+
+	c.mux.HandleFunc("DELETE /api/v1/posts/{postID}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestContextKey{}, r)
+		input, err := decodeDeletePostInput(r)
+		if err != nil {
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		err = c.controller.DeletePost(ctx, input)
+		if err != nil {
+
+			c.errorHandler.HandleError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if c.schemaEndpoint {
+		c.mux.HandleFunc("GET /_overtime/schema", func(w http.ResponseWriter, r *http.Request) {
+			c.writeResponse(w, r, http.StatusOK, schemaDescription)
+		})
+	}
+
+	return c
+}
+
+// schemaEndpointDescription describes a single endpoint of the schema
+// served by WithSchemaEndpoint.
+type schemaEndpointDescription struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+var schemaDescription = struct {
+	Version   string                      `json:"version"`
+	Endpoints []schemaEndpointDescription `json:"endpoints"`
+}{
+	Version: SchemaVersion,
+	Endpoints: []schemaEndpointDescription{
+		{Name: "GetCommentByID", Method: "GET", Path: "/api/v1/comments/{commentID}"},
+		{Name: "ListPosts", Method: "GET", Path: "/api/v1/posts"},
+		{Name: "CreatePost", Method: "POST", Path: "/api/v1/posts"},
+		{Name: "GetPostByID", Method: "GET", Path: "/api/v1/posts/{postID}"},
+		{Name: "DeletePost", Method: "DELETE", Path: "/api/v1/posts/{postID}"},
+	},
+}
+
+// ServeHTTP serves the provided request by routing it to the correct
+// endpoint and invoking the correct method on the controller.
+func (c *Coordinator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mux.ServeHTTP(w, r)
+}
+
+type requestContextKey struct{}
+
+// RequestFromContext returns the request being served from the context
+// passed to controllers and resolvers, giving access to headers and other
+// request details.
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestContextKey{}).(*http.Request)
+	return r, ok
+}
+
+// writeResponse encodes the body before writing the status so that
+// encoding failures can still be rendered by the error handler.
+func (c *Coordinator) writeResponse(w http.ResponseWriter, r *http.Request, status int, body any) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		c.errorHandler.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(encoded)
+}
+
+/*******************************************************************************************
+* Errors generated here
+*******************************************************************************************/
+
+// HTTPError is an error that controls the response sent to the client. It
+// can be returned by controllers and resolvers to respond with a status
+// other than 500.
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// NewHTTPError returns an HTTPError with the given status, machine readable
+// code, and human readable message.
+func NewHTTPError(status int, code string, message string) *HTTPError {
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+// NotFound returns an HTTPError that responds with a 404.
+func NotFound(message string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, "not_found", message)
+}
+
+// Unauthorized returns an HTTPError that responds with a 401.
+func Unauthorized(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, "unauthorized", message)
+}
+
+// Forbidden returns an HTTPError that responds with a 403.
+func Forbidden(message string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, "forbidden", message)
+}
+
+// Conflict returns an HTTPError that responds with a 409.
+func Conflict(message string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, "conflict", message)
+}
+
+// NotImplemented returns an HTTPError that responds with a 501, it is
+// returned by the stubs added to impl.go for new methods.
+func NotImplemented(method string) *HTTPError {
+	return NewHTTPError(http.StatusNotImplemented, "not_implemented", method+" is not implemented")
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// ErrorHandler renders errors returned by input decoding, controllers, and
+// resolvers.
+type ErrorHandler interface {
+	HandleError(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// ErrorHandlerFunc allows a plain function to be used as an ErrorHandler.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+
+func (f ErrorHandlerFunc) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	f(w, r, err)
+}
+
+// ErrorResponse is the JSON envelope DefaultErrorHandler responds with.
+type ErrorResponse struct {
+	Error ErrorDetails `json:"error"`
+}
+
+// ErrorDetails describes the error in an ErrorResponse.
+type ErrorDetails struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Fields  []InvalidField `json:"fields,omitempty"`
+}
+
+// DefaultErrorHandler renders errors as an ErrorResponse. HTTPError values
+// use their own status, code, and message while any other error responds
+// with a 500 without exposing the underlying error to the client.
+type DefaultErrorHandler struct{}
+
+func (DefaultErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	details := ErrorDetails{Code: "internal_error", Message: "Internal server error"}
+
+	var httpErr *HTTPError
+	var inputErr *InputError
+
+	switch {
+	case errors.As(err, &httpErr):
+		status = httpErr.Status
+		details = ErrorDetails{Code: httpErr.Code, Message: httpErr.Message}
+	case errors.As(err, &inputErr):
+		status = http.StatusBadRequest
+		details = ErrorDetails{
+			Code:    "invalid_input",
+			Message: "The request contains invalid input",
+			Fields:  inputErr.Fields,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{Error: details})
+}
+
+/*******************************************************************************************
+* Controllers generated here
+*******************************************************************************************/
+
+type Controller interface {
+	// Responds with 200 Comment
+	GetCommentByID(ctx context.Context, input *GetCommentByIDInput) (*Comment, error)
+	// Responds with 200 []Post
+	ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error)
+	// Responds with 201 Post
+	CreatePost(ctx context.Context, input *CreatePostInput) (*Post, error)
+	// Responds with 200 Post, 404 NotFoundError
+	GetPostByID(ctx context.Context, input *GetPostByIDInput) (*Post, error)
+	// Responds with 204
+	DeletePost(ctx context.Context, input *DeletePostInput) error
+}
+
+/*******************************************************************************************
+* Inputs generated here
+*******************************************************************************************/
+
+// GetCommentByIDInput holds the decoded arguments for GetCommentByID.
+type GetCommentByIDInput struct {
+	CommentID string `json:"-"`
+}
+
+func decodeGetCommentByIDInput(r *http.Request) (*GetCommentByIDInput, error) {
+	d := newInputDecoder(r, false)
+	input := &GetCommentByIDInput{}
+
+	input.CommentID = pathParam[string](d, "commentID")
+
+	return input, d.err()
+}
+
+// ListPostsInput holds the decoded arguments for ListPosts.
+type ListPostsInput struct {
+	Page *int `json:"-"`
+}
+
+func decodeListPostsInput(r *http.Request) (*ListPostsInput, error) {
+	d := newInputDecoder(r, false)
+	input := &ListPostsInput{}
+
+	input.Page = optionalQueryParam[int](d, "page")
+
+	return input, d.err()
+}
+
+// CreatePostInput holds the decoded arguments for CreatePost.
+type CreatePostInput struct {
+	Body  string `json:"body"`
+	Draft *bool  `json:"draft,omitempty"`
+	Tags  []Tag  `json:"tags,omitempty"`
+}
+
+func decodeCreatePostInput(r *http.Request) (*CreatePostInput, error) {
+	d := newInputDecoder(r, true)
+	input := &CreatePostInput{}
+
+	input.Body = bodyField[string](d, "body", true)
+	input.Draft = optionalBodyField[bool](d, "draft")
+	input.Tags = bodyField[[]Tag](d, "tags", false)
+
+	return input, d.err()
+}
+
+// GetPostByIDInput holds the decoded arguments for GetPostByID.
+type GetPostByIDInput struct {
+	PostID int64 `json:"-"`
+}
+
+func decodeGetPostByIDInput(r *http.Request) (*GetPostByIDInput, error) {
+	d := newInputDecoder(r, false)
+	input := &GetPostByIDInput{}
+
+	input.PostID = pathParam[int64](d, "postID")
+
+	return input, d.err()
+}
+
+// DeletePostInput holds the decoded arguments for DeletePost.
+type DeletePostInput struct {
+	PostID int64 `json:"-"`
+}
+
+func decodeDeletePostInput(r *http.Request) (*DeletePostInput, error) {
+	d := newInputDecoder(r, false)
+	input := &DeletePostInput{}
+
+	input.PostID = pathParam[int64](d, "postID")
+
+	return input, d.err()
+}
+
+// InvalidField describes a single input field that failed validation.
+type InvalidField struct {
+	Field   string `json:"field"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// InputError is returned when a request contains invalid input. It lists
+// every invalid field so clients can correct all of them at once.
+type InputError struct {
+	Fields []InvalidField `json:"fields"`
+}
+
+func (e *InputError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = fmt.Sprintf("%s %s %s", field.Source, field.Field, field.Message)
+	}
+
+	return "invalid input: " + strings.Join(messages, ", ")
+}
+
+// inputDecoder reads the arguments of an endpoint from a request, recording
+// every invalid field instead of stopping at the first one.
+type inputDecoder struct {
+	r      *http.Request
+	query  url.Values
+	body   map[string]json.RawMessage
+	fields []InvalidField
+}
+
+func newInputDecoder(r *http.Request, hasBody bool) *inputDecoder {
+	d := &inputDecoder{r: r, query: r.URL.Query()}
+
+	if hasBody {
+		err := json.NewDecoder(r.Body).Decode(&d.body)
+		if err != nil && !errors.Is(err, io.EOF) {
+			d.invalid("body", "", "must be a JSON object")
+		}
+	}
+
+	return d
+}
+
+func (d *inputDecoder) invalid(source string, name string, message string) {
+	d.fields = append(d.fields, InvalidField{Field: name, Source: source, Message: message})
+}
+
+func (d *inputDecoder) err() error {
+	if len(d.fields) == 0 {
+		return nil
+	}
+
+	return &InputError{Fields: d.fields}
+}
+
+func pathParam[T any](d *inputDecoder, name string) T {
+	value, err := parseParam[T](d.r.PathValue(name))
+	if err != nil {
+		d.invalid("path", name, "must be "+describeType[T]())
+	}
+
+	return value
+}
+
+func queryParam[T any](d *inputDecoder, name string) T {
+	var value T
+	if !d.query.Has(name) {
+		d.invalid("query", name, "is required")
+		return value
+	}
+
+	value, err := parseParam[T](d.query.Get(name))
+	if err != nil {
+		d.invalid("query", name, "must be "+describeType[T]())
+	}
+
+	return value
+}
+
+func optionalQueryParam[T any](d *inputDecoder, name string) *T {
+	if !d.query.Has(name) {
+		return nil
+	}
+
+	value, err := parseParam[T](d.query.Get(name))
+	if err != nil {
+		d.invalid("query", name, "must be "+describeType[T]())
+		return nil
+	}
+
+	return &value
+}
+
+func bodyField[T any](d *inputDecoder, name string, required bool) T {
+	var value T
+	raw, ok := d.body[name]
+	if !ok || string(raw) == "null" {
+		if required {
+			d.invalid("body", name, "is required")
+		}
+
+		return value
+	}
+
+	if err := json.Unmarshal(raw, &value); err != nil {
+		d.invalid("body", name, "must be "+describeType[T]())
+	}
+
+	return value
+}
+
+func optionalBodyField[T any](d *inputDecoder, name string) *T {
+	if raw, ok := d.body[name]; !ok || string(raw) == "null" {
+		return nil
+	}
+
+	value := bodyField[T](d, name, false)
+	return &value
+}
+
+// enumInput is implemented by the enum types of the schema so inputs can
+// be checked against their declared values.
+type enumInput interface {
+	parse(value string) error
+	describe() string
+}
+
+// parseParam converts the raw string value of a path or query parameter
+// into the type of the input field it populates.
+func parseParam[T any](raw string) (T, error) {
+	var value T
+	var err error
+
+	switch v := any(&value).(type) {
+	case *string:
+		*v = raw
+	case *int:
+		*v, err = strconv.Atoi(raw)
+	case *int64:
+		*v, err = strconv.ParseInt(raw, 10, 64)
+	case *bool:
+		*v, err = strconv.ParseBool(raw)
+	case *float64:
+		*v, err = strconv.ParseFloat(raw, 64)
+	case enumInput:
+		err = v.parse(raw)
+	default:
+		err = fmt.Errorf("unsupported parameter type %T", value)
+	}
+
+	return value, err
+}
+
+// describeType returns a human readable description of the expected type
+// of an input field for validation messages.
+func describeType[T any]() string {
+	var value T
+
+	if enum, ok := any(&value).(enumInput); ok {
+		return enum.describe()
+	}
+
+	// Lists of enums are described by their values too, since the
+	// elements are checked against them when decoding the list.
+	if t := reflect.TypeOf(value); t != nil && t.Kind() == reflect.Slice {
+		if enum, ok := reflect.New(t.Elem()).Interface().(enumInput); ok {
+			return "a list of values that are each " + enum.describe()
+		}
+	}
+
+	switch any(value).(type) {
+	case string:
+		return "a string"
+	case int, int64:
+		return "an integer"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	default:
+		return "valid JSON"
+	}
+}
+
+/*******************************************************************************************
+* Enums generated here
+*******************************************************************************************/
+
+type Tag string
+
+const (
+	TagNews    Tag = "news"
+	TagRelease Tag = "release"
+)
+
+// IsValid returns true if the value is one of the declared Tag
+// values.
+func (e Tag) IsValid() bool {
+	switch e {
+	case TagNews, TagRelease:
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalJSON encodes the value as a JSON string, returning an error when
+// it is not one of the declared values.
+func (e Tag) MarshalJSON() ([]byte, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid Tag %q, must be %s", string(e), e.describe())
+	}
+
+	return json.Marshal(string(e))
+}
+
+// UnmarshalJSON decodes a JSON string, returning an error when it is not
+// one of the declared values.
+func (e *Tag) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	return e.parse(value)
+}
+
+func (e *Tag) parse(value string) error {
+	if !Tag(value).IsValid() {
+		return fmt.Errorf("invalid Tag %q, must be %s", value, e.describe())
+	}
+
+	*e = Tag(value)
+
+	return nil
+}
+
+func (Tag) describe() string {
+	return "one of news or release"
+}
+
+/*******************************************************************************************
+* Types generated here
+*******************************************************************************************/
+
+type User struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Posts []*Post `json:"posts" resolver:"ResolveUserPosts"`
+}
+
+// ResolveForUser populates the resolver fields of the given records and
+// of every record nested within them. Records are resolved level by level
+// so each resolver is called once per level of nesting. Independent
+// resolvers run concurrently, at most maxConcurrency at a time, or without a
+// limit when maxConcurrency is 0. Resolution stops as soon as ctx is done.
+func ResolveForUser(ctx context.Context, records []*User, resolver Resolver, maxConcurrency int) error {
+	return resolveLevels(ctx, &resolutionLevel{User: records}, resolver, maxConcurrency)
+}
+
+// resolveUserFields schedules the resolvers populating the fields of
+// records and adds the resolved records that need resolving themselves to
+// the next level.
+func resolveUserFields(records []*User, resolver Resolver, group *resolverGroup, next *resolutionLevel) {
+	ids := make([]int64, 0, len(records))
+	seen := make(map[int64]bool, len(records))
+
+	for _, record := range records {
+		if record != nil && !seen[record.ID] {
+			seen[record.ID] = true
+			ids = append(ids, record.ID)
+		}
+	}
+
+	group.Go(func(ctx context.Context) error {
+		res, err := resolver.ResolveUserPosts(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+
+			if val, ok := res[record.ID]; ok {
+				record.Posts = val
+				next.addPost(val...)
+			}
+		}
+
+		return nil
+	})
+
+}
+
+type Comment struct {
+	ID     string `json:"id"`
+	Body   string `json:"body"`
+	Author *User  `json:"author" resolver:"ResolveCommentAuthor"`
+}
+
+// ResolveForComment populates the resolver fields of the given records and
+// of every record nested within them. Records are resolved level by level
+// so each resolver is called once per level of nesting. Independent
+// resolvers run concurrently, at most maxConcurrency at a time, or without a
+// limit when maxConcurrency is 0. Resolution stops as soon as ctx is done.
+func ResolveForComment(ctx context.Context, records []*Comment, resolver Resolver, maxConcurrency int) error {
+	return resolveLevels(ctx, &resolutionLevel{Comment: records}, resolver, maxConcurrency)
+}
+
+// resolveCommentFields schedules the resolvers populating the fields of
+// records and adds the resolved records that need resolving themselves to
+// the next level.
+func resolveCommentFields(records []*Comment, resolver Resolver, group *resolverGroup, next *resolutionLevel) {
+	ids := make([]string, 0, len(records))
+	seen := make(map[string]bool, len(records))
+
+	for _, record := range records {
+		if record != nil && !seen[record.ID] {
+			seen[record.ID] = true
+			ids = append(ids, record.ID)
+		}
+	}
+
+	group.Go(func(ctx context.Context) error {
+		res, err := resolver.ResolveCommentAuthor(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+
+			if val, ok := res[record.ID]; ok {
+				record.Author = val
+				next.addUser(val)
+			}
+		}
+
+		return nil
+	})
+
+}
+
+type Post struct {
+	ID       int64      `json:"id"`
+	Body     string     `json:"body"`
+	Author   *User      `json:"author" resolver:"ResolvePostAuthor"`
+	Comments []*Comment `json:"comments" resolver:"ResolvePostComments"`
+}
+
+// ResolveForPost populates the resolver fields of the given records and
+// of every record nested within them. Records are resolved level by level
+// so each resolver is called once per level of nesting. Independent
+// resolvers run concurrently, at most maxConcurrency at a time, or without a
+// limit when maxConcurrency is 0. Resolution stops as soon as ctx is done.
+func ResolveForPost(ctx context.Context, records []*Post, resolver Resolver, maxConcurrency int) error {
+	return resolveLevels(ctx, &resolutionLevel{Post: records}, resolver, maxConcurrency)
+}
+
+// resolvePostFields schedules the resolvers populating the fields of
+// records and adds the resolved records that need resolving themselves to
+// the next level.
+func resolvePostFields(records []*Post, resolver Resolver, group *resolverGroup, next *resolutionLevel) {
+	ids := make([]int64, 0, len(records))
+	seen := make(map[int64]bool, len(records))
+
+	for _, record := range records {
+		if record != nil && !seen[record.ID] {
+			seen[record.ID] = true
+			ids = append(ids, record.ID)
+		}
+	}
+
+	group.Go(func(ctx context.Context) error {
+		res, err := resolver.ResolvePostAuthor(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+
+			if val, ok := res[record.ID]; ok {
+				record.Author = val
+				next.addUser(val)
+			}
+		}
+
+		return nil
+	})
+
+	group.Go(func(ctx context.Context) error {
+		res, err := resolver.ResolvePostComments(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+
+			if val, ok := res[record.ID]; ok {
+				record.Comments = val
+				next.addComment(val...)
+			}
+		}
+
+		return nil
+	})
+
+}
+
+type NotFoundError struct {
+	Message string `json:"message"`
+}
+
+// Error allows NotFoundError to be returned by controllers declaring it as
+// a response.
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// maxResolutionDepth limits how deeply nested records are resolved, guarding
+// against resolvers that keep returning records never seen before.
+const maxResolutionDepth = 32
+
+// resolutionLevel holds the records found at a single level of nesting
+// that still need their resolver fields populated.
+type resolutionLevel struct {
+	mu      sync.Mutex
+	User    []*User
+	Comment []*Comment
+	Post    []*Post
+}
+
+func (l *resolutionLevel) empty() bool {
+	return len(l.User) == 0 && len(l.Comment) == 0 && len(l.Post) == 0
+}
+
+func (l *resolutionLevel) addUser(records ...*User) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.User = append(l.User, records...)
+}
+
+func (l *resolutionLevel) addComment(records ...*Comment) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Comment = append(l.Comment, records...)
+}
+
+func (l *resolutionLevel) addPost(records ...*Post) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Post = append(l.Post, records...)
+}
+
+// resolvedRecords holds the ids of the records resolved at earlier levels,
+// so records referring back to them, like the posts of a post's author, are
+// not resolved again and types referring to each other stop resolving.
+type resolvedRecords struct {
+	User    map[int64]bool
+	Comment map[string]bool
+	Post    map[int64]bool
+}
+
+// filterUser returns the records that were not resolved at an earlier
+// level and marks them as resolved.
+func (r *resolvedRecords) filterUser(records []*User) []*User {
+	if r.User == nil {
+		r.User = make(map[int64]bool, len(records))
+	}
+
+	unresolved := make([]*User, 0, len(records))
+	for _, record := range records {
+		if record != nil && !r.User[record.ID] {
+			unresolved = append(unresolved, record)
+		}
+	}
+
+	// Records are only marked once the level is filtered, so copies of a
+	// record within the same level are all resolved.
+	for _, record := range unresolved {
+		r.User[record.ID] = true
+	}
+
+	return unresolved
+}
+
+// filterComment returns the records that were not resolved at an earlier
+// level and marks them as resolved.
+func (r *resolvedRecords) filterComment(records []*Comment) []*Comment {
+	if r.Comment == nil {
+		r.Comment = make(map[string]bool, len(records))
+	}
+
+	unresolved := make([]*Comment, 0, len(records))
+	for _, record := range records {
+		if record != nil && !r.Comment[record.ID] {
+			unresolved = append(unresolved, record)
+		}
+	}
+
+	// Records are only marked once the level is filtered, so copies of a
+	// record within the same level are all resolved.
+	for _, record := range unresolved {
+		r.Comment[record.ID] = true
+	}
+
+	return unresolved
+}
+
+// filterPost returns the records that were not resolved at an earlier
+// level and marks them as resolved.
+func (r *resolvedRecords) filterPost(records []*Post) []*Post {
+	if r.Post == nil {
+		r.Post = make(map[int64]bool, len(records))
+	}
+
+	unresolved := make([]*Post, 0, len(records))
+	for _, record := range records {
+		if record != nil && !r.Post[record.ID] {
+			unresolved = append(unresolved, record)
+		}
+	}
+
+	// Records are only marked once the level is filtered, so copies of a
+	// record within the same level are all resolved.
+	for _, record := range unresolved {
+		r.Post[record.ID] = true
+	}
+
+	return unresolved
+}
+
+// resolveLevels resolves the records of each level, batching every record
+// of a type into a single call per resolver, until no records remain.
+// Records already resolved at an earlier level are skipped. The resolvers
+// of a level run concurrently and every error they return is reported.
+func resolveLevels(ctx context.Context, level *resolutionLevel, resolver Resolver, maxConcurrency int) error {
+	resolved := &resolvedRecords{}
+
+	for depth := 0; !level.empty(); depth++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if depth == maxResolutionDepth {
+			return fmt.Errorf("resolving records exceeded %d levels of nesting", maxResolutionDepth)
+		}
+
+		next := &resolutionLevel{}
+		group := newResolverGroup(ctx, maxConcurrency)
+		if records := resolved.filterUser(level.User); len(records) > 0 {
+			resolveUserFields(records, resolver, group, next)
+		}
+		if records := resolved.filterComment(level.Comment); len(records) > 0 {
+			resolveCommentFields(records, resolver, group, next)
+		}
+		if records := resolved.filterPost(level.Post); len(records) > 0 {
+			resolvePostFields(records, resolver, group, next)
+		}
+
+		if err := group.Wait(); err != nil {
+			return err
+		}
+
+		level = next
+	}
+
+	return nil
+}
+
+// resolverGroup runs resolver calls concurrently, limiting how many run at
+// once and collecting every error returned. The context passed to calls is
+// canceled when the request is done or any call fails, so the remaining
+// calls can stop early.
+type resolverGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+	mu     sync.Mutex
+	errs   []error
+}
+
+func newResolverGroup(ctx context.Context, maxConcurrency int) *resolverGroup {
+	ctx, cancel := context.WithCancel(ctx)
+	group := &resolverGroup{ctx: ctx, cancel: cancel}
+	if maxConcurrency > 0 {
+		group.sem = make(chan struct{}, maxConcurrency)
+	}
+
+	return group
+}
+
+func (g *resolverGroup) Go(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if g.sem != nil {
+			select {
+			case g.sem <- struct{}{}:
+				defer func() { <-g.sem }()
+			case <-g.ctx.Done():
+				g.fail(g.ctx.Err())
+				return
+			}
+		}
+
+		if err := g.ctx.Err(); err != nil {
+			g.fail(err)
+			return
+		}
+
+		if err := fn(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// fail records err and cancels the remaining calls. Cancellation errors
+// caused by an earlier failure are not recorded.
+func (g *resolverGroup) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) > 0 && errors.Is(err, context.Canceled) {
+		return
+	}
+
+	g.errs = append(g.errs, err)
+	g.cancel()
+}
+
+// Wait blocks until every scheduled call returns and joins their errors.
+func (g *resolverGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+
+	return errors.Join(g.errs...)
+}
+
+/*******************************************************************************************
+* Resolvers generated here
+*******************************************************************************************/
+
+type Resolver interface {
+	// Populates the Posts field for the User type
+	ResolveUserPosts(ctx context.Context, userIDs []int64) (map[int64][]*Post, error)
+	// Populates the Author field for the Comment type
+	ResolveCommentAuthor(ctx context.Context, commentIDs []string) (map[string]*User, error)
+	// Populates the Author field for the Post type
+	ResolvePostAuthor(ctx context.Context, postIDs []int64) (map[int64]*User, error)
+	// Populates the Comments field for the Post type
+	ResolvePostComments(ctx context.Context, postIDs []int64) (map[int64][]*Comment, error)
+}
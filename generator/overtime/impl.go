@@ -2,32 +2,80 @@
 // Your implementation for resolvers and endpoints should go here
 package overtime
 
-import "net/http"
+import "context"
 
 type RootResolver struct{}
 
 var _ Resolver = (*RootResolver)(nil)
 
-func (r *RootResolver) ResolvePostComments(ids []int64) (map[int64][]*Comment, error) {
+func (r *RootResolver) ResolvePostComments(ctx context.Context, ids []int64) (map[int64][]*Comment, error) {
 	return map[int64][]*Comment{
-		1: {{ID: 1, Body: "comment 1"}, {ID: 2, Body: "comment 2"}},
+		1: {{ID: "c1", Body: "comment 1"}, {ID: "c2", Body: "comment 2"}},
 	}, nil
 }
 
+func (r *RootResolver) ResolvePostAuthor(ctx context.Context, ids []int64) (map[int64]*User, error) {
+	authors := make(map[int64]*User, len(ids))
+	for _, id := range ids {
+		authors[id] = &User{ID: 1, Name: "author 1"}
+	}
+
+	return authors, nil
+}
+
+func (r *RootResolver) ResolveCommentAuthor(ctx context.Context, ids []string) (map[string]*User, error) {
+	authors := make(map[string]*User, len(ids))
+	for _, id := range ids {
+		authors[id] = &User{ID: 2, Name: "author 2"}
+	}
+
+	return authors, nil
+}
+
+func (r *RootResolver) ResolveUserPosts(ctx context.Context, ids []int64) (map[int64][]*Post, error) {
+	posts := make(map[int64][]*Post, len(ids))
+	for _, id := range ids {
+		posts[id] = []*Post{{ID: 1, Body: "post 1"}}
+	}
+
+	return posts, nil
+}
+
 type RootController struct{}
 
 var _ Controller = (*RootController)(nil)
 
-func (c *RootController) GetCommentByID(w http.ResponseWriter, r *http.Request) (*Comment, error) {
+func (c *RootController) GetCommentByID(ctx context.Context, input *GetCommentByIDInput) (*Comment, error) {
 	return &Comment{
-		ID:   1,
+		ID:   input.CommentID,
 		Body: "comment 1",
 	}, nil
 }
 
-func (c *RootController) GetPostByID(w http.ResponseWriter, r *http.Request) (*Post, error) {
+func (c *RootController) ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error) {
+	return []*Post{
+		{ID: 1, Body: "post 1"},
+	}, nil
+}
+
+func (c *RootController) CreatePost(ctx context.Context, input *CreatePostInput) (*Post, error) {
 	return &Post{
-		ID:   1,
+		ID:   2,
+		Body: input.Body,
+	}, nil
+}
+
+func (c *RootController) GetPostByID(ctx context.Context, input *GetPostByIDInput) (*Post, error) {
+	if input.PostID != 1 {
+		return nil, &NotFoundError{Message: "post not found"}
+	}
+
+	return &Post{
+		ID:   input.PostID,
 		Body: "post 1",
 	}, nil
 }
+
+func (c *RootController) DeletePost(ctx context.Context, input *DeletePostInput) error {
+	return nil
+}
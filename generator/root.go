@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	rootResolver   = "RootResolver"
+	rootController = "RootController"
+)
+
+// declarations are the types and methods already defined in the
+// implementation package, keyed by name and by `Type.Method` respectively.
+type declarations struct {
+	types   map[string]bool
+	methods map[string]bool
+}
+
+// Root returns the implementation file used to bootstrap a project, with
+// RootResolver and RootController stubs for every Resolver and Controller
+// method.
+func (g *Go) Root() (io.Reader, error) {
+	buf := new(bytes.Buffer)
+
+	buf.WriteString("// This file is generated only once to bootstrap the project\n")
+	buf.WriteString("// Your implementation for resolvers and endpoints should go here\n")
+	buf.WriteString(fmt.Sprintf("package %s\n\n", g.PackageName))
+
+	stubs := g.stubs(&declarations{types: map[string]bool{}, methods: map[string]bool{}})
+	if strings.Contains(stubs, "context.") {
+		buf.WriteString("import \"context\"\n\n")
+	}
+
+	buf.WriteString(stubs)
+
+	return formatCode(buf, nil)
+}
+
+// UpdateRoot returns the contents of the impl.go file in dir with stubs
+// appended for the Resolver and Controller methods that are not implemented
+// anywhere in the package yet. Existing code is left untouched, only an
+// import of context is added when the stubs need it, and nil is returned when
+// nothing is missing.
+func (g *Go) UpdateRoot(dir string) (io.Reader, error) {
+	filename := filepath.Join(dir, "impl.go")
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := parseDeclarations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stubs := g.stubs(existing)
+	if stubs == "" {
+		return nil, nil
+	}
+
+	file, err := goparser.ParseFile(token.NewFileSet(), filename, src, goparser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	importsContext := false
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path == "context" && (spec.Name == nil || spec.Name.Name == "context") {
+			importsContext = true
+		}
+	}
+
+	// Only the stubs are formatted, so the hand-written code and its imports
+	// are kept exactly as they are.
+	formatted, err := format.Source([]byte(stubs))
+	if err != nil {
+		return nil, sourceError(err, []byte(stubs), nil)
+	}
+
+	buf := new(bytes.Buffer)
+
+	// A separate import declaration is added after the package clause so the
+	// existing imports, and their comments, are not rewritten.
+	packageEnd := int(file.Name.End()) - 1
+	buf.Write(src[:packageEnd])
+	if !importsContext && strings.Contains(stubs, "context.") {
+		buf.WriteString("\n\nimport \"context\"")
+	}
+	buf.Write(src[packageEnd:])
+	if !bytes.HasSuffix(src, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	buf.Write(bytes.TrimSpace(formatted))
+	buf.WriteString("\n")
+
+	return buf, nil
+}
+
+// stubs returns the declarations of RootResolver and RootController, and
+// their methods, that are missing from existing. Stubs return a
+// NotImplemented error so the package compiles until they are implemented.
+func (g *Go) stubs(existing *declarations) string {
+	buf := new(bytes.Buffer)
+
+	if !existing.types[rootResolver] {
+		buf.WriteString("type RootResolver struct{}\n\n")
+		buf.WriteString("var _ Resolver = (*RootResolver)(nil)\n\n")
+	}
+
+	for _, resolver := range g.TypesNeedingResolvers() {
+		if existing.methods[rootResolver+"."+resolver.MethodName()] {
+			continue
+		}
+
+		fmt.Fprintf(buf, "func (r *RootResolver) %s(%s) (%s, error) {\n", resolver.MethodName(), resolver.Arguments(), resolver.ReturnType())
+		fmt.Fprintf(buf, "\treturn nil, NotImplemented(%q)\n}\n\n", resolver.MethodName())
+	}
+
+	if !existing.types[rootController] {
+		buf.WriteString("type RootController struct{}\n\n")
+		buf.WriteString("var _ Controller = (*RootController)(nil)\n\n")
+	}
+
+	for _, endpoint := range g.Endpoints() {
+		if existing.methods[rootController+"."+endpoint.MethodName()] {
+			continue
+		}
+
+		fmt.Fprintf(buf, "func (c *RootController) %s(%s) %s {\n", endpoint.MethodName(), endpoint.Arguments(), endpoint.Results())
+		if endpoint.HasBody() {
+			fmt.Fprintf(buf, "\treturn nil, NotImplemented(%q)\n}\n\n", endpoint.MethodName())
+		} else {
+			fmt.Fprintf(buf, "\treturn NotImplemented(%q)\n}\n\n", endpoint.MethodName())
+		}
+	}
+
+	return buf.String()
+}
+
+// parseDeclarations returns the types and methods declared by the
+// hand-written files in dir, skipping generated.go and tests.
+func parseDeclarations(dir string) (*declarations, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	existing := &declarations{types: map[string]bool{}, methods: map[string]bool{}}
+	fset := token.NewFileSet()
+
+	for _, filename := range filenames {
+		base := filepath.Base(filename)
+		if base == "generated.go" || strings.HasSuffix(base, "_test.go") {
+			continue
+		}
+
+		file, err := goparser.ParseFile(fset, filename, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						existing.types[spec.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					continue
+				}
+
+				if receiver := receiverName(decl.Recv.List[0].Type); receiver != "" {
+					existing.methods[receiver+"."+decl.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+// receiverName returns the name of the type of a method receiver, unwrapping
+// pointers and type parameters.
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blakewilliams/overtime/internal/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootStubsEveryMethod(t *testing.T) {
+	dir := writeGeneratedPackage(t)
+
+	gen := newBlogGenerator(t)
+	r, err := gen.Root()
+	require.NoError(t, err)
+
+	impl, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), impl, 0o644))
+
+	require.Contains(t, string(impl), `import "context"`)
+	require.Contains(t, string(impl), "func (r *RootResolver) ResolveCommentAuthor(ctx context.Context, commentIDs []string) (map[string]*User, error) {\n\treturn nil, NotImplemented(\"ResolveCommentAuthor\")\n}")
+	require.Contains(t, string(impl), "func (c *RootController) ListPosts(ctx context.Context, input *ListPostsInput) ([]*Post, error) {\n\treturn nil, NotImplemented(\"ListPosts\")\n}")
+	require.Contains(t, string(impl), "func (c *RootController) DeletePost(ctx context.Context, input *DeletePostInput) error {\n\treturn NotImplemented(\"DeletePost\")\n}")
+
+	requireVet(t, dir)
+}
+
+func TestUpdateRootAddsMissingStubs(t *testing.T) {
+	dir := writeGeneratedPackage(t)
+
+	impl := `// Hand written implementation
+package golden
+
+import "fmt"
+
+// Hand written code is kept as is, even when it isn't formatted.
+var answer   = 42
+
+type RootResolver struct{}
+
+type RootController struct{}
+
+// ListPosts is already implemented.
+func (c RootController) ListPosts(_ context.Context, input *ListPostsInput) ([]*Post, error) {
+	return nil, fmt.Errorf("no posts")
+}
+`
+	// Methods implemented in other files of the package are not stubbed.
+	resolvers := `package golden
+
+import "context"
+
+func (r *RootResolver) ResolvePostAuthor(ctx context.Context, postIDs []int64) (map[int64]*User, error) {
+	return nil, nil
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), []byte(impl), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "resolvers.go"), []byte(resolvers), 0o644))
+
+	gen := newBlogGenerator(t)
+	r, err := gen.UpdateRoot(dir)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	updated, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), updated, 0o644))
+
+	out := string(updated)
+	require.True(t, strings.HasPrefix(out, "// Hand written implementation\npackage golden\n\nimport \"context\""+strings.TrimPrefix(impl, "// Hand written implementation\npackage golden")+"\n"), out)
+	require.Equal(t, 1, strings.Count(out, "type RootController struct{}"))
+	require.Equal(t, 1, strings.Count(out, ") ListPosts("))
+	require.NotContains(t, out, "ResolvePostAuthor")
+	require.True(t, strings.HasSuffix(out, "}\n"))
+	require.False(t, strings.HasSuffix(out, "\n\n"))
+	require.Contains(t, out, "func (r *RootResolver) ResolvePostComments(")
+	require.Contains(t, out, "func (c *RootController) GetPostByID(")
+
+	requireVet(t, dir)
+
+	r, err = gen.UpdateRoot(dir)
+	require.NoError(t, err)
+	require.Nil(t, r, "Nothing should be added once every method is implemented")
+}
+
+func newBlogGenerator(t *testing.T) *Go {
+	t.Helper()
+
+	schema, err := parser.ParseFile(e2eSchema)
+	require.NoError(t, err)
+
+	gen := NewGo(schema)
+	gen.PackageName = "golden"
+
+	return gen
+}
+
+// writeGeneratedPackage writes the generated code for the e2e schema into a
+// new module, returning the package directory.
+func writeGeneratedPackage(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/golden\n\ngo 1.22\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "generated.go"), []byte(generateFile(t, e2eSchema, "golden")), 0o644))
+
+	return dir
+}
+
+func requireVet(t *testing.T, dir string) {
+	t.Helper()
+
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
@@ -0,0 +1,320 @@
+// Config describes the settings used to generate a gateway for a project,
+// stored in an overtime.yaml file at the root of the project.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blakewilliams/overtime/generator"
+	"gopkg.in/yaml.v3"
+)
+
+// Filename is the name of the project config file.
+const Filename = "overtime.yaml"
+
+// ErrNotFound is returned by Find when no config file exists in the
+// directory or any of its parents.
+var ErrNotFound = errors.New("no " + Filename + " found")
+
+// Generators are the names of the generators that can be enabled, mapped to
+// whether they are implemented yet.
+var Generators = map[string]bool{
+	"server":  true,
+	"client":  false,
+	"openapi": false,
+}
+
+// Options are the options accepted by each generator, which are all booleans.
+var Options = map[string][]string{
+	"server": {"schema_endpoint"},
+}
+
+type (
+	// Config is the representation of an overtime.yaml file.
+	Config struct {
+		// Dir is the directory containing the config file. Schema and output
+		// paths are relative to it.
+		Dir string `yaml:"-"`
+		// Schemas are the schema files the gateway is generated from,
+		// relative to the config file. They can be glob patterns, e.g.
+		// `schemas/*.yaml`, and are merged into a single schema.
+		Schemas []string `yaml:"schemas"`
+		// Module is the import path of the Go module the project lives in.
+		// It defaults to the module declared by the go.mod next to the config.
+		Module string `yaml:"module,omitempty"`
+		Output Output `yaml:"output"`
+		// Generators are the enabled generators and their options, keyed by
+		// name. Only the server is generated when none are configured.
+		Generators map[string]*Generator `yaml:"generators,omitempty"`
+	}
+
+	// Output describes where generated code is written.
+	Output struct {
+		// Package is the name of the generated package.
+		Package string `yaml:"package"`
+		// Directory is the directory the package directory is created in,
+		// relative to the config file.
+		Directory string `yaml:"directory,omitempty"`
+	}
+
+	// Generator configures a single generator. Its output defaults to the
+	// top level output of the config.
+	Generator struct {
+		Output `yaml:",inline"`
+		// Options tweak the generated code. The options each generator
+		// accepts are listed in Options.
+		Options map[string]any `yaml:"options,omitempty"`
+	}
+)
+
+// Find returns the path of the config file in dir or the closest of its
+// parents, stopping at the root of the Go module containing dir. ErrNotFound
+// is returned when there is none.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, Filename)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", ErrNotFound
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates the config file at path, filling in defaults.
+func Load(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("Invalid config %s: %w", path, err)
+	}
+
+	c.Dir = filepath.Dir(path)
+	c.setDefaults()
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid config %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Default returns the config used when a project has no config file, with
+// paths relative to dir.
+func Default(dir string, schemas ...string) *Config {
+	c := &Config{Dir: dir, Schemas: schemas}
+	c.setDefaults()
+
+	return c
+}
+
+func (c *Config) setDefaults() {
+	if c.Output.Package == "" {
+		c.Output.Package = generator.DefaultPackageName
+	}
+
+	if len(c.Generators) == 0 {
+		c.Generators = map[string]*Generator{"server": {}}
+	}
+
+	for name, gen := range c.Generators {
+		if gen == nil {
+			gen = &Generator{}
+			c.Generators[name] = gen
+		}
+
+		if gen.Package == "" {
+			gen.Package = c.Output.Package
+		}
+
+		if gen.Directory == "" {
+			gen.Directory = c.Output.Directory
+		}
+	}
+
+	if c.Module == "" {
+		c.Module = ReadModule(filepath.Join(c.Dir, "go.mod"))
+	}
+}
+
+// Validate returns an error describing every problem with the config.
+func (c *Config) Validate() error {
+	problems := make([]string, 0)
+
+	if len(c.Schemas) == 0 {
+		problems = append(problems, "`schemas` must list at least one schema file")
+	}
+
+	for _, name := range c.GeneratorNames() {
+		implemented, known := Generators[name]
+		switch {
+		case !known:
+			problems = append(problems, fmt.Sprintf("Unknown generator %s, expected one of %s", name, strings.Join(generatorNames(), ", ")))
+		case !implemented:
+			problems = append(problems, fmt.Sprintf("The %s generator is not supported yet", name))
+		default:
+			problems = append(problems, c.Generators[name].validateOptions(name)...)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+func (g *Generator) validateOptions(generator string) []string {
+	known := make(map[string]bool, len(Options[generator]))
+	for _, name := range Options[generator] {
+		known[name] = true
+	}
+
+	names := make([]string, 0, len(g.Options))
+	for name := range g.Options {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	problems := make([]string, 0)
+	for _, name := range names {
+		if !known[name] {
+			problems = append(problems, fmt.Sprintf("Unknown option %s for the %s generator", name, generator))
+			continue
+		}
+
+		if _, ok := g.Options[name].(bool); !ok {
+			problems = append(problems, fmt.Sprintf("Option %s of the %s generator must be true or false", name, generator))
+		}
+	}
+
+	return problems
+}
+
+// Enabled returns whether the named boolean option is set to true.
+func (g *Generator) Enabled(option string) bool {
+	enabled, _ := g.Options[option].(bool)
+
+	return enabled
+}
+
+// SchemaPaths returns the paths of the schema files, resolved relative to the
+// config file.
+func (c *Config) SchemaPaths() []string {
+	paths := make([]string, len(c.Schemas))
+	for i, schema := range c.Schemas {
+		paths[i] = c.resolve(schema)
+	}
+
+	return paths
+}
+
+// GeneratorNames returns the names of the enabled generators, sorted.
+func (c *Config) GeneratorNames() []string {
+	names := make([]string, 0, len(c.Generators))
+	for name := range c.Generators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// PackageDir returns the directory the package of the named generator is
+// written to.
+func (c *Config) PackageDir(name string) string {
+	output := c.Output
+	if gen, ok := c.Generators[name]; ok && gen != nil {
+		output = gen.Output
+	}
+
+	return filepath.Join(c.resolve(output.Directory), output.Package)
+}
+
+func (c *Config) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.Dir, path)
+}
+
+// Marshal returns the YAML representation of the config.
+func (c *Config) Marshal() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("# Settings used by `overtime generate`\n")
+
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(c); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func generatorNames() []string {
+	names := make([]string, 0, len(Generators))
+	for name := range Generators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ReadModule returns the module declared by the go.mod at path, or an empty
+// string when there is none.
+func ReadModule(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+
+	return ""
+}
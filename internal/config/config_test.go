@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFillsInDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/gateway\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, Filename), "schemas: [schema.yaml]\n")
+
+	c, err := Load(filepath.Join(dir, Filename))
+	require.NoError(t, err)
+
+	require.Equal(t, dir, c.Dir)
+	require.Equal(t, "example.com/gateway", c.Module)
+	require.Equal(t, []string{filepath.Join(dir, "schema.yaml")}, c.SchemaPaths())
+	require.Equal(t, []string{"server"}, c.GeneratorNames())
+	require.Equal(t, filepath.Join(dir, "overtime"), c.PackageDir("server"))
+}
+
+func TestLoadAppliesGeneratorOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `
+schemas:
+  - schemas/api.yaml
+module: example.com/gateway
+output:
+  package: api
+  directory: internal
+generators:
+  server:
+    directory: pkg
+    options:
+      schema_endpoint: true
+`)
+
+	c, err := Load(filepath.Join(dir, Filename))
+	require.NoError(t, err)
+
+	require.Equal(t, "example.com/gateway", c.Module)
+	require.Equal(t, []string{filepath.Join(dir, "schemas", "api.yaml")}, c.SchemaPaths())
+	require.Equal(t, filepath.Join(dir, "pkg", "api"), c.PackageDir("server"))
+	require.True(t, c.Generators["server"].Enabled("schema_endpoint"))
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `
+generators:
+  server:
+  openapi:
+  grpc:
+`)
+
+	_, err := Load(filepath.Join(dir, Filename))
+	require.EqualError(t, err, "Invalid config "+filepath.Join(dir, Filename)+": `schemas` must list at least one schema file\n"+
+		"Unknown generator grpc, expected one of client, openapi, server\n"+
+		"The openapi generator is not supported yet")
+}
+
+func TestLoadRejectsUnknownOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `
+schemas: [schema.yaml]
+generators:
+  server:
+    options:
+      schema_endpoint: "yes"
+      schema_path: /schema
+`)
+
+	_, err := Load(filepath.Join(dir, Filename))
+	require.EqualError(t, err, "Invalid config "+filepath.Join(dir, Filename)+": Option schema_endpoint of the server generator must be true or false\n"+
+		"Unknown option schema_path for the server generator")
+}
+
+func TestFindSearchesParentsUntilTheModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "project", "cmd", "server")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+
+	writeFile(t, filepath.Join(root, Filename), "schemas: [schema.yaml]\n")
+
+	path, err := Find(nested)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, Filename), path)
+
+	writeFile(t, filepath.Join(root, "project", "go.mod"), "module example.com/project\n")
+
+	_, err = Find(nested)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
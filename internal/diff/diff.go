@@ -0,0 +1,285 @@
+// Diff compares two versions of a schema, classifying every change by whether
+// it breaks existing clients.
+package diff
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/blakewilliams/overtime/internal/parser"
+)
+
+type (
+	// Kind is the kind of a change between two schemas.
+	Kind string
+
+	// Change is a single difference between two schemas.
+	Change struct {
+		Kind Kind
+		// Breaking is true when the change can break existing clients.
+		Breaking bool
+		Message  string
+		// Old and New are the positions of the changed element in each
+		// schema, they are empty when the element doesn't exist in one of
+		// them.
+		Old parser.Pos
+		New parser.Pos
+	}
+)
+
+// The kinds of changes reported by Compare.
+const (
+	EndpointAdded      Kind = "endpoint-added"
+	EndpointRemoved    Kind = "endpoint-removed"
+	PathChanged        Kind = "path-changed"
+	ReturnTypeChanged  Kind = "return-type-changed"
+	TypeRemoved        Kind = "type-removed"
+	FieldAdded         Kind = "field-added"
+	OptionalFieldAdded Kind = "optional-field-added"
+	FieldRemoved       Kind = "field-removed"
+	FieldMadeRequired  Kind = "field-made-required"
+	FieldMadeOptional  Kind = "field-made-optional"
+	TypeChanged        Kind = "type-changed"
+	EnumRemoved        Kind = "enum-removed"
+	EnumValueRemoved   Kind = "enum-value-removed"
+	EnumValueAdded     Kind = "enum-value-added"
+)
+
+// Compare returns every change between the old and current schema, ordered by
+// the position of the changed element in the old schema, followed by the
+// elements only present in the current schema.
+//
+// Endpoints are matched by method and path, falling back to their name so
+// moved endpoints are reported as a changed path instead of a removal.
+func Compare(old *parser.Schema, current *parser.Schema) []Change {
+	changes := make([]Change, 0)
+	matched := make(map[*parser.Endpoint]bool, len(current.Endpoints))
+
+	newByName := make(map[string]*parser.Endpoint, len(current.Endpoints))
+	for _, e := range current.Endpoints {
+		newByName[e.Name] = e
+	}
+
+	for _, oldEndpoint := range old.SortedEndpoints() {
+		route := oldEndpoint.Method + " " + oldEndpoint.Path
+
+		newEndpoint, ok := current.Endpoints[route]
+		if !ok {
+			newEndpoint, ok = newByName[oldEndpoint.Name]
+			if ok && (matched[newEndpoint] || old.Endpoints[newEndpoint.Method+" "+newEndpoint.Path] != nil) {
+				ok = false
+			}
+		}
+
+		if !ok {
+			changes = append(changes, Change{
+				Kind:     EndpointRemoved,
+				Breaking: true,
+				Message:  fmt.Sprintf("Endpoint %s was removed", route),
+				Old:      oldEndpoint.Pos,
+			})
+			continue
+		}
+
+		matched[newEndpoint] = true
+		changes = append(changes, compareEndpoints(oldEndpoint, newEndpoint)...)
+	}
+
+	for _, oldType := range old.SortedTypes() {
+		newType, ok := current.Types[oldType.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Kind:     TypeRemoved,
+				Breaking: true,
+				Message:  fmt.Sprintf("Type %s was removed", oldType.Name),
+				Old:      oldType.Pos,
+			})
+			continue
+		}
+
+		changes = append(changes, compareFields("type "+oldType.Name, oldType.Fields, newType.Fields, false)...)
+	}
+
+	for _, oldEnum := range old.SortedEnums() {
+		newEnum, ok := current.Enums[oldEnum.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Kind:     EnumRemoved,
+				Breaking: true,
+				Message:  fmt.Sprintf("Enum %s was removed", oldEnum.Name),
+				Old:      oldEnum.Pos,
+			})
+			continue
+		}
+
+		changes = append(changes, compareEnums(oldEnum, newEnum)...)
+	}
+
+	for _, newEndpoint := range current.SortedEndpoints() {
+		if matched[newEndpoint] {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Kind:    EndpointAdded,
+			Message: fmt.Sprintf("Endpoint %s %s was added", newEndpoint.Method, newEndpoint.Path),
+			New:     newEndpoint.Pos,
+		})
+	}
+
+	return changes
+}
+
+// HasBreaking returns true if any of the changes can break existing clients.
+func HasBreaking(changes []Change) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compareEndpoints(old *parser.Endpoint, current *parser.Endpoint) []Change {
+	changes := make([]Change, 0)
+	route := old.Method + " " + old.Path
+
+	if old.Method != current.Method || old.Path != current.Path {
+		changes = append(changes, Change{
+			Kind:     PathChanged,
+			Breaking: true,
+			Message:  fmt.Sprintf("Endpoint %s moved to %s %s", route, current.Method, current.Path),
+			Old:      old.Pos,
+			New:      current.Pos,
+		})
+	}
+
+	if old.Returns != current.Returns || old.Status != current.Status {
+		changes = append(changes, Change{
+			Kind:     ReturnTypeChanged,
+			Breaking: true,
+			Message:  fmt.Sprintf("Endpoint %s returns %s instead of %s", route, describeResponse(current), describeResponse(old)),
+			Old:      old.Pos,
+			New:      current.Pos,
+		})
+	}
+
+	return append(changes, compareFields("the input of "+route, old.Args, current.Args, true)...)
+}
+
+// compareFields compares the fields of a type or the arguments of an
+// endpoint. Input fields are sent by clients, so adding a required input or
+// making one required breaks them, while types are read by clients, so making
+// a field optional breaks them instead.
+func compareFields(owner string, old map[string]parser.Field, current map[string]parser.Field, input bool) []Change {
+	changes := make([]Change, 0)
+
+	for _, oldField := range parser.SortedFields(old) {
+		newField, ok := current[oldField.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Kind:     FieldRemoved,
+				Breaking: true,
+				Message:  fmt.Sprintf("Field `%s` was removed from %s", oldField.Name, owner),
+				Old:      oldField.Pos,
+			})
+			continue
+		}
+
+		if oldField.Type != newField.Type {
+			changes = append(changes, Change{
+				Kind:     TypeChanged,
+				Breaking: true,
+				Message:  fmt.Sprintf("Field `%s` of %s changed from %s to %s", oldField.Name, owner, oldField.Type, newField.Type),
+				Old:      oldField.Pos,
+				New:      newField.Pos,
+			})
+		}
+
+		switch {
+		case oldField.IsOptional && !newField.IsOptional:
+			changes = append(changes, Change{
+				Kind:     FieldMadeRequired,
+				Breaking: input,
+				Message:  fmt.Sprintf("Field `%s` of %s was made required", oldField.Name, owner),
+				Old:      oldField.Pos,
+				New:      newField.Pos,
+			})
+		case !oldField.IsOptional && newField.IsOptional:
+			changes = append(changes, Change{
+				Kind:     FieldMadeOptional,
+				Breaking: !input,
+				Message:  fmt.Sprintf("Field `%s` of %s was made optional", oldField.Name, owner),
+				Old:      oldField.Pos,
+				New:      newField.Pos,
+			})
+		}
+	}
+
+	for _, newField := range parser.SortedFields(current) {
+		if _, ok := old[newField.Name]; ok {
+			continue
+		}
+
+		if newField.IsOptional {
+			changes = append(changes, Change{
+				Kind:    OptionalFieldAdded,
+				Message: fmt.Sprintf("Optional field `%s` was added to %s", newField.Name, owner),
+				New:     newField.Pos,
+			})
+			continue
+		}
+
+		changes = append(changes, Change{
+			Kind:     FieldAdded,
+			Breaking: input,
+			Message:  fmt.Sprintf("Field `%s` was added to %s", newField.Name, owner),
+			New:      newField.Pos,
+		})
+	}
+
+	return changes
+}
+
+// compareEnums compares the values of an enum. Removing a value breaks
+// clients sending or expecting it, while clients can keep using the existing
+// values when one is added.
+func compareEnums(old *parser.Enum, current *parser.Enum) []Change {
+	changes := make([]Change, 0)
+
+	for _, value := range old.Values {
+		if !slices.Contains(current.Values, value) {
+			changes = append(changes, Change{
+				Kind:     EnumValueRemoved,
+				Breaking: true,
+				Message:  fmt.Sprintf("Value `%s` was removed from enum %s", value, old.Name),
+				Old:      old.Pos,
+				New:      current.Pos,
+			})
+		}
+	}
+
+	for _, value := range current.Values {
+		if !slices.Contains(old.Values, value) {
+			changes = append(changes, Change{
+				Kind:    EnumValueAdded,
+				Message: fmt.Sprintf("Value `%s` was added to enum %s", value, old.Name),
+				Old:     old.Pos,
+				New:     current.Pos,
+			})
+		}
+	}
+
+	return changes
+}
+
+// describeResponse returns the status and body of the successful response of
+// an endpoint, e.g. `200 Post`.
+func describeResponse(e *parser.Endpoint) string {
+	if e.Returns == "" {
+		return fmt.Sprintf("%d", e.Status)
+	}
+
+	return fmt.Sprintf("%d %s", e.Status, e.Returns)
+}
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blakewilliams/overtime/internal/parser"
+	"github.com/stretchr/testify/require"
+)
+
+const oldSchema = `
+types:
+  User:
+    fields:
+      id: int64
+      name: string
+      email?: string
+  Post:
+    fields:
+      id: int64
+      title: string
+      author: User
+  Draft:
+    fields:
+      id: int64
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    input:
+      page?: int
+    response:
+      body: "[]Post"
+  "GET /posts/:postID":
+    name: GetPost
+    input:
+      postID: int64
+    response:
+      body: Post
+  "POST /posts":
+    name: CreatePost
+    input:
+      title: string
+      draft?: bool
+    response:
+      status: 201
+      body: Post
+  "DELETE /posts/:postID":
+    name: DeletePost
+    input:
+      postID: int64
+    response:
+      status: 204`
+
+const newSchema = `
+types:
+  User:
+    fields:
+      id: int64
+      name?: string
+      email: string
+  Post:
+    fields:
+      id: string
+      author: User
+      body?: string
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    input:
+      page: int
+    response:
+      body: "[]User"
+  "GET /articles/:postID":
+    name: GetPost
+    input:
+      postID: string
+    response:
+      body: Post
+  "POST /posts":
+    name: CreatePost
+    input:
+      title: string
+      body: string
+      tags?: string
+    response:
+      status: 201
+      body: Post
+  "GET /users":
+    name: ListUsers
+    response:
+      body: "[]User"`
+
+func TestCompare(t *testing.T) {
+	old, err := parser.Parse(strings.NewReader(oldSchema))
+	require.NoError(t, err)
+
+	current, err := parser.Parse(strings.NewReader(newSchema))
+	require.NoError(t, err)
+
+	changes := Compare(old, current)
+
+	type result struct {
+		Kind     Kind
+		Breaking bool
+		Message  string
+	}
+
+	results := make([]result, len(changes))
+	for i, c := range changes {
+		results[i] = result{c.Kind, c.Breaking, c.Message}
+	}
+
+	require.Equal(t, []result{
+		{ReturnTypeChanged, true, "Endpoint GET /posts returns 200 []User instead of 200 []Post"},
+		{FieldMadeRequired, true, "Field `page` of the input of GET /posts was made required"},
+		{PathChanged, true, "Endpoint GET /posts/:postID moved to GET /articles/:postID"},
+		{TypeChanged, true, "Field `postID` of the input of GET /posts/:postID changed from int64 to string"},
+		{FieldRemoved, true, "Field `draft` was removed from the input of POST /posts"},
+		{FieldAdded, true, "Field `body` was added to the input of POST /posts"},
+		{OptionalFieldAdded, false, "Optional field `tags` was added to the input of POST /posts"},
+		{EndpointRemoved, true, "Endpoint DELETE /posts/:postID was removed"},
+		{FieldMadeOptional, true, "Field `name` of type User was made optional"},
+		{FieldMadeRequired, false, "Field `email` of type User was made required"},
+		{TypeChanged, true, "Field `id` of type Post changed from int64 to string"},
+		{FieldRemoved, true, "Field `title` was removed from type Post"},
+		{OptionalFieldAdded, false, "Optional field `body` was added to type Post"},
+		{TypeRemoved, true, "Type Draft was removed"},
+		{EndpointAdded, false, "Endpoint GET /users was added"},
+	}, results)
+
+	require.True(t, HasBreaking(changes))
+	require.Equal(t, parser.Pos{Line: 37, Column: 3}, changes[7].Old)
+	require.Equal(t, parser.Pos{Line: 20, Column: 3}, changes[2].New)
+}
+
+func TestCompareIdenticalSchemas(t *testing.T) {
+	schema, err := parser.Parse(strings.NewReader(oldSchema))
+	require.NoError(t, err)
+
+	changes := Compare(schema, schema)
+	require.Empty(t, changes)
+	require.False(t, HasBreaking(changes))
+}
+
+func TestCompareEnums(t *testing.T) {
+	old, err := parser.Parse(strings.NewReader(`
+enums:
+  PostState: [draft, published, archived]
+  Role: [admin, member]
+  Visibility: [public, private]`))
+	require.NoError(t, err)
+
+	current, err := parser.Parse(strings.NewReader(`
+enums:
+  PostState: [draft, published, scheduled]
+  Visibility: [public, private, unlisted]`))
+	require.NoError(t, err)
+
+	changes := Compare(old, current)
+
+	type result struct {
+		Kind     Kind
+		Breaking bool
+		Message  string
+	}
+
+	results := make([]result, len(changes))
+	for i, c := range changes {
+		results[i] = result{c.Kind, c.Breaking, c.Message}
+	}
+
+	require.Equal(t, []result{
+		{EnumValueRemoved, true, "Value `archived` was removed from enum PostState"},
+		{EnumValueAdded, false, "Value `scheduled` was added to enum PostState"},
+		{EnumRemoved, true, "Enum Role was removed"},
+		{EnumValueAdded, false, "Value `unlisted` was added to enum Visibility"},
+	}, results)
+
+	require.True(t, HasBreaking(changes))
+	require.Equal(t, parser.Pos{Line: 4, Column: 3}, changes[2].Old)
+	require.False(t, HasBreaking(Compare(current, current)))
+}
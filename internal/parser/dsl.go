@@ -0,0 +1,541 @@
+package parser
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DSLExtension is the file extension of schemas written in the overtime DSL.
+const DSLExtension = ".overtime"
+
+// dslParser builds a Schema from the tokens of an `.overtime` schema. Errors
+// are recorded and parsing resumes at the next line, so every problem in the
+// schema is reported at once.
+type dslParser struct {
+	tokens  []token
+	current int
+	errs    ErrorList
+}
+
+// ParseDSL reads a schema written in the overtime DSL from s:
+//
+//	version "0.1.0"
+//	import "comments.overtime"
+//
+//	enum PostState { draft, published }
+//
+//	// Post is a single blog post.
+//	type Post extends Timestamps {
+//	  id: int64
+//	  title: string
+//	  state: PostState
+//	  comments: []Comment
+//	}
+//
+//	GET "/api/posts/:postID" {
+//	  name GetPostByID
+//	  input {
+//	    postID: int64
+//	  }
+//	  returns Post
+//	  returns 404 NotFoundError
+//	}
+//
+// Every problem found while parsing and validating the schema is returned as
+// an ErrorList.
+func ParseDSL(s io.Reader) (*Schema, error) {
+	l := newLoader()
+	if err := l.loadReader("", s, decodeDSL); err != nil {
+		return nil, err
+	}
+
+	return l.finish()
+}
+
+// decodeDSL parses a schema written in the DSL without validating it,
+// recording every problem found in errs.
+func decodeDSL(filename string, s io.Reader, errs *ErrorList) (*Schema, error) {
+	src, err := io.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dslParser{}
+	p.tokens = newLexer(filename, string(src), &p.errs).tokens()
+	schema := p.parseSchema()
+	*errs = append(*errs, p.errs...)
+
+	return schema, nil
+}
+
+func (p *dslParser) peek() token {
+	return p.tokens[p.current]
+}
+
+func (p *dslParser) next() token {
+	t := p.tokens[p.current]
+	if t.kind != tokenEOF {
+		p.current++
+	}
+
+	return t
+}
+
+func (p *dslParser) errorf(t token, format string, args ...any) {
+	p.errs.Add(t.pos, format, args...)
+}
+
+// accept consumes the next token if it is of the given kind.
+func (p *dslParser) accept(kind tokenKind) bool {
+	if p.peek().kind != kind {
+		return false
+	}
+
+	p.next()
+
+	return true
+}
+
+// expect consumes the next token, recording an error when it is not of the
+// given kind.
+func (p *dslParser) expect(kind tokenKind, what string) (token, bool) {
+	t := p.peek()
+	if t.kind != kind {
+		p.errorf(t, "Expected %s, found %s", what, t)
+		return t, false
+	}
+
+	return p.next(), true
+}
+
+func (p *dslParser) skipNewlines() {
+	for p.accept(tokenNewline) {
+	}
+}
+
+// skipLine skips the rest of the current line, including any blocks opened
+// on it, so parsing can resume after an error. A closing brace is left for
+// the enclosing block.
+func (p *dslParser) skipLine() {
+	depth := 0
+	for {
+		switch p.peek().kind {
+		case tokenEOF:
+			return
+		case tokenNewline:
+			if depth == 0 {
+				return
+			}
+		case tokenLBrace:
+			depth++
+		case tokenRBrace:
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+
+		p.next()
+	}
+}
+
+// endStatement records an error when a statement is followed by anything
+// other than the end of the line or block.
+func (p *dslParser) endStatement() {
+	switch t := p.peek(); t.kind {
+	case tokenNewline, tokenRBrace, tokenEOF:
+	default:
+		p.errorf(t, "Expected a newline, found %s", t)
+		p.skipLine()
+	}
+}
+
+// parseBlock parses a `{ ... }` block, calling statement for every line
+// inside of it.
+func (p *dslParser) parseBlock(statement func()) {
+	if _, ok := p.expect(tokenLBrace, "`{`"); !ok {
+		p.skipLine()
+		return
+	}
+
+	for {
+		p.skipNewlines()
+
+		switch t := p.peek(); t.kind {
+		case tokenRBrace:
+			p.next()
+			return
+		case tokenEOF:
+			p.errorf(t, "Expected `}`, found %s", t)
+			return
+		}
+
+		start := p.current
+		statement()
+		p.endStatement()
+
+		// Always make progress, even when a statement consumed nothing.
+		if p.current == start {
+			p.next()
+		}
+	}
+}
+
+func (p *dslParser) parseSchema() *Schema {
+	schema := &Schema{
+		Endpoints: make(map[string]*Endpoint),
+		Types:     make(map[string]*Type),
+		Enums:     make(map[string]*Enum),
+	}
+
+	for {
+		p.skipNewlines()
+
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return schema
+		case t.kind == tokenIdent && t.value == "type":
+			p.parseType(schema)
+		case t.kind == tokenIdent && t.value == "enum":
+			p.parseEnum(schema)
+		case t.kind == tokenIdent && t.value == "version" && p.tokens[p.current+1].kind == tokenString:
+			p.parseVersion(schema)
+		case t.kind == tokenIdent && t.value == "import" && p.tokens[p.current+1].kind == tokenString:
+			p.next()
+			path := p.next()
+			schema.imports = append(schema.imports, schemaImport{Pos: path.pos, Path: path.value})
+			p.endStatement()
+		case t.kind == tokenIdent:
+			p.parseEndpoint(schema)
+		default:
+			p.errorf(t, "Expected a type or endpoint declaration, found %s", t)
+			p.next()
+			p.skipLine()
+		}
+	}
+}
+
+// parseVersion parses the version of the schema, e.g. `version "0.1.0"`.
+func (p *dslParser) parseVersion(schema *Schema) {
+	keyword := p.next()
+	version := p.next()
+
+	if schema.Version != "" {
+		p.errorf(keyword, "`version` is already defined at %s", schema.VersionPos)
+	} else {
+		schema.Version = version.value
+		schema.VersionPos = version.pos
+	}
+
+	p.endStatement()
+}
+
+// parseType parses a type declaration, e.g. `type Post extends Base {}`.
+// Fields are declared directly in the type or inside of a `fields` block.
+func (p *dslParser) parseType(schema *Schema) {
+	keyword := p.next()
+
+	name, ok := p.expect(tokenIdent, "a type name")
+	if !ok {
+		p.skipLine()
+		return
+	}
+
+	t := &Type{
+		Index:      len(schema.Types),
+		Pos:        name.pos,
+		Name:       name.value,
+		Fields:     make(map[string]Field),
+		DocComment: keyword.doc,
+	}
+
+	if next := p.peek(); next.kind == tokenIdent && next.value == "extends" {
+		p.next()
+		t.ExtendsPos = next.pos
+
+		for {
+			base, ok := p.expect(tokenIdent, "a type to extend")
+			if !ok {
+				p.skipLine()
+				return
+			}
+
+			t.Extends = append(t.Extends, base.value)
+
+			if !p.accept(tokenComma) {
+				break
+			}
+		}
+	}
+
+	owner := "Type " + t.Name
+	index := 0
+	field := func() {
+		p.parseField(t.Fields, owner, index)
+		index++
+	}
+
+	p.parseBlock(func() {
+		if t := p.peek(); t.kind == tokenIdent && t.value == "fields" && p.tokens[p.current+1].kind == tokenLBrace {
+			p.next()
+			p.parseBlock(field)
+			return
+		}
+
+		field()
+	})
+
+	if existing, ok := schema.Types[t.Name]; ok {
+		p.errorf(name, "Type %s is already defined at %s", t.Name, existing.Pos)
+		return
+	}
+
+	schema.Types[t.Name] = t
+}
+
+// parseEnum parses an enum declaration, listing its values one per line or
+// separated by commas, e.g. `enum PostState { draft, published }`.
+func (p *dslParser) parseEnum(schema *Schema) {
+	keyword := p.next()
+
+	name, ok := p.expect(tokenIdent, "an enum name")
+	if !ok {
+		p.skipLine()
+		return
+	}
+
+	enum := &Enum{
+		Index:      len(schema.Enums),
+		Pos:        name.pos,
+		Name:       name.value,
+		DocComment: keyword.doc,
+	}
+
+	p.parseBlock(func() {
+		for {
+			value, ok := p.expect(tokenIdent, "an enum value")
+			if !ok {
+				p.skipLine()
+				return
+			}
+
+			enum.Values = append(enum.Values, value.value)
+
+			if !p.accept(tokenComma) {
+				return
+			}
+		}
+	})
+
+	if existing, ok := schema.Enums[enum.Name]; ok {
+		p.errorf(name, "Enum %s is already defined at %s", enum.Name, existing.Pos)
+		return
+	}
+
+	schema.Enums[enum.Name] = enum
+}
+
+// parseField parses a `name?: Type` field, adding it to fields.
+func (p *dslParser) parseField(fields map[string]Field, owner string, index int) {
+	name, ok := p.expect(tokenIdent, "a field name")
+	if !ok {
+		p.skipLine()
+		return
+	}
+
+	optional := p.accept(tokenQuestion)
+
+	if _, ok := p.expect(tokenColon, "`:`"); !ok {
+		p.skipLine()
+		return
+	}
+
+	fieldType, ok := p.parseTypeRef()
+	if !ok {
+		p.skipLine()
+		return
+	}
+
+	if existing, ok := fields[name.value]; ok {
+		p.errorf(name, "%s already defines `%s` at %s", owner, name.value, existing.Pos)
+		return
+	}
+
+	fields[name.value] = Field{
+		Index:      index,
+		Pos:        name.pos,
+		Name:       name.value,
+		Type:       fieldType,
+		IsOptional: optional,
+		DocComment: name.doc,
+	}
+}
+
+// parseTypeRef parses a type name or a list of one, e.g. `[]Post`.
+func (p *dslParser) parseTypeRef() (string, bool) {
+	prefix := ""
+	if p.accept(tokenLBracket) {
+		if _, ok := p.expect(tokenRBracket, "`]`"); !ok {
+			return "", false
+		}
+
+		prefix = "[]"
+	}
+
+	name, ok := p.expect(tokenIdent, "a type")
+	if !ok {
+		return "", false
+	}
+
+	return prefix + name.value, true
+}
+
+// parseEndpoint parses an endpoint declaration, e.g. `GET "/api/posts" {}`.
+func (p *dslParser) parseEndpoint(schema *Schema) {
+	method := p.next()
+
+	path, ok := p.expect(tokenString, "a quoted path")
+	if !ok {
+		p.skipLine()
+		return
+	}
+
+	rawPath := method.value + " " + path.value
+	e := &Endpoint{
+		Index:      len(schema.Endpoints),
+		Pos:        method.pos,
+		Method:     method.value,
+		Path:       path.value,
+		Args:       make(map[string]Field),
+		DocComment: method.doc,
+	}
+
+	p.parseBlock(func() {
+		t := p.next()
+		if t.kind != tokenIdent {
+			p.errorf(t, "Expected `name`, `input`, or `returns`, found %s", t)
+			p.skipLine()
+			return
+		}
+
+		switch t.value {
+		case "name":
+			name, ok := p.expect(tokenIdent, "an endpoint name")
+			if !ok {
+				p.skipLine()
+				return
+			}
+
+			if e.Name != "" {
+				p.errorf(t, "`name` is already defined for %s", rawPath)
+				return
+			}
+
+			e.Name = name.value
+		case "input":
+			index := 0
+			p.parseBlock(func() {
+				p.parseField(e.Args, "The input of "+rawPath, index)
+				index++
+			})
+		case "returns":
+			p.parseReturns(t, e, rawPath)
+		default:
+			p.errorf(t, "Unknown statement %s for %s, expected `name`, `input`, or `returns`", t, rawPath)
+			p.skipLine()
+		}
+	})
+
+	if e.Name == "" {
+		e.Name = endpointName(e.Method, e.Path)
+	}
+
+	sort.SliceStable(e.Responses, func(i, j int) bool {
+		return e.Responses[i].Status < e.Responses[j].Status
+	})
+	e.resolve()
+
+	if conflict := schema.endpointConflict(e); conflict != "" {
+		p.errorf(method, "%s", conflict)
+		return
+	}
+
+	schema.Endpoints[e.Method+" "+e.Path] = e
+}
+
+// endpointName derives the name of an endpoint declared without one from its
+// method and path, e.g. GetPostsByPostID for GET /posts/:postID.
+func endpointName(method string, path string) string {
+	name := new(strings.Builder)
+	name.WriteString(pascalCase(strings.ToLower(method)))
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			name.WriteString("By")
+		}
+
+		name.WriteString(pascalCase(segment))
+	}
+
+	return name.String()
+}
+
+// pascalCase joins the words of s, separated by anything but letters and
+// digits, capitalizing the first letter of each.
+func pascalCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = Capitalize(word)
+	}
+
+	return strings.Join(words, "")
+}
+
+// parseReturns parses a `returns [status] [Type]` statement. The status
+// defaults to 200 and responses without a type have no body.
+func (p *dslParser) parseReturns(keyword token, e *Endpoint, rawPath string) {
+	response := Response{Pos: keyword.pos, Status: 200}
+
+	hasStatus := false
+	if t := p.peek(); t.kind == tokenInt {
+		p.next()
+		hasStatus = true
+
+		status, err := strconv.Atoi(t.value)
+		if err != nil {
+			p.errorf(t, "Invalid status %s for %s", t.value, rawPath)
+			return
+		}
+		response.Status = status
+	}
+
+	if t := p.peek(); t.kind == tokenIdent || t.kind == tokenLBracket {
+		body, ok := p.parseTypeRef()
+		if !ok {
+			p.skipLine()
+			return
+		}
+		response.Body = body
+	}
+
+	if !hasStatus && response.Body == "" {
+		p.errorf(keyword, "`returns` must define a status or a type for %s", rawPath)
+		return
+	}
+
+	for _, existing := range e.Responses {
+		if existing.Status == response.Status {
+			p.errorf(keyword, "Status %d is already returned by %s at %s", response.Status, rawPath, existing.Pos)
+			return
+		}
+	}
+
+	e.Responses = append(e.Responses, response)
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSL(t *testing.T) {
+	schema, err := ParseDSL(strings.NewReader(`version "0.1.0"
+// Post is a single blog post.
+type Post {
+  fields {
+    title: string
+    // The ID of the post
+    id: int64 // trailing comments are ignored
+  }
+}
+
+type Comment { id: int64 }
+
+GET "/posts/:postID" {
+  name GetPost
+  input {
+    postID: int64
+    page?: int
+  }
+  returns Post
+  returns 404 Comment
+}
+
+DELETE "/posts/:postID" {
+  name DeletePost
+  input { postID: int64 }
+  returns 204
+}`))
+	require.NoError(t, err)
+
+	require.Equal(t, "0.1.0", schema.Version)
+	require.Equal(t, Pos{Line: 1, Column: 9}, schema.VersionPos)
+
+	post := schema.Types["Post"]
+	require.Equal(t, 0, post.Index)
+	require.Equal(t, Pos{Line: 3, Column: 6}, post.Pos)
+	require.Equal(t, "Post is a single blog post.", post.DocComment)
+	require.Equal(t, 1, schema.Types["Comment"].Index)
+
+	require.Equal(t, 0, post.Fields["title"].Index)
+	require.Equal(t, 1, post.Fields["id"].Index)
+	require.Equal(t, Pos{Line: 7, Column: 5}, post.Fields["id"].Pos)
+	require.Equal(t, "The ID of the post", post.Fields["id"].DocComment)
+
+	endpoint := schema.Endpoints["GET /posts/:postID"]
+	require.Equal(t, Pos{Line: 13, Column: 1}, endpoint.Pos)
+	require.Equal(t, "GetPost", endpoint.Name)
+	require.Equal(t, 1, endpoint.Args["page"].Index)
+	require.True(t, endpoint.Args["page"].IsOptional)
+	require.Equal(t, InputPath, endpoint.Args["postID"].Source)
+	require.Equal(t, InputQuery, endpoint.Args["page"].Source)
+	require.Equal(t, 200, endpoint.Status)
+	require.Equal(t, "Post", endpoint.Returns)
+	require.Equal(t, []Response{
+		{Pos: Pos{Line: 19, Column: 3}, Status: 200, Body: "Post"},
+		{Pos: Pos{Line: 20, Column: 3}, Status: 404, Body: "Comment"},
+	}, endpoint.Responses)
+
+	deletePost := schema.Endpoints["DELETE /posts/:postID"]
+	require.Equal(t, 1, deletePost.Index)
+	require.Equal(t, 204, deletePost.Status)
+	require.Equal(t, "", deletePost.Returns)
+}
+
+func TestParseDSLExtends(t *testing.T) {
+	_, err := ParseDSL(strings.NewReader(`type Timestamps {
+  createdAt: string
+}
+
+type Comment { id: int64 }
+
+type DetailedComment extends Comment, Timestamps {
+  body: string
+}
+
+type Broken extends Comment, {
+  body: string
+}`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+	require.EqualError(t, errs, "11:30: Expected a type to extend, found `{`")
+
+	schema, err := ParseDSL(strings.NewReader(`type Timestamps {
+  createdAt: string
+}
+
+type Comment { id: int64 }
+
+type DetailedComment extends Comment, Timestamps {
+  body: string
+}`))
+	require.NoError(t, err)
+
+	detailed := schema.Types["DetailedComment"]
+	require.Equal(t, []string{"Comment", "Timestamps"}, detailed.Extends)
+	require.Equal(t, Pos{Line: 7, Column: 22}, detailed.ExtendsPos)
+	require.Equal(t, 0, detailed.Fields["id"].Index)
+	require.Equal(t, 1, detailed.Fields["createdAt"].Index)
+	require.Equal(t, 2, detailed.Fields["body"].Index)
+	require.Equal(t, "Timestamps", detailed.Fields["createdAt"].InheritedFrom)
+}
+
+func TestParseDSLEnums(t *testing.T) {
+	schema, err := ParseDSL(strings.NewReader(`// PostState is the publication state of a post.
+enum PostState {
+  draft
+  published, archived
+}
+
+enum Visibility { public, private }
+
+type Post {
+  id: int64
+  state: PostState
+}`))
+	require.NoError(t, err)
+
+	state := schema.Enums["PostState"]
+	require.Equal(t, Pos{Line: 2, Column: 6}, state.Pos)
+	require.Equal(t, []string{"draft", "published", "archived"}, state.Values)
+	require.Equal(t, "PostState is the publication state of a post.", state.DocComment)
+	require.Equal(t, []string{"public", "private"}, schema.Enums["Visibility"].Values)
+
+	_, err = ParseDSL(strings.NewReader(`enum PostState { draft, }`))
+	require.EqualError(t, err, "1:25: Expected an enum value, found `}`")
+}
+
+func TestParseDSLDerivesMissingEndpointNames(t *testing.T) {
+	schema, err := ParseDSL(strings.NewReader(`type Post { id: int64 }
+
+GET "/api/posts" {
+  returns []Post
+}
+
+GET "/api/v1/posts/:postID" {
+  input { postID: int64 }
+  returns Post
+}
+
+POST "/api/user-posts" {
+  returns 201 Post
+}`))
+	require.NoError(t, err)
+
+	require.Equal(t, "GetApiPosts", schema.Endpoints["GET /api/posts"].Name)
+	require.Equal(t, "GetApiV1PostsByPostID", schema.Endpoints["GET /api/v1/posts/:postID"].Name)
+	require.Equal(t, "PostApiUserPosts", schema.Endpoints["POST /api/user-posts"].Name)
+
+	require.Equal(t, "GetÜbersicht", endpointName("GET", "/übersicht"))
+}
+
+func TestParseDSLRecoversFromErrors(t *testing.T) {
+	_, err := ParseDSL(strings.NewReader(`type User {
+  id int64
+  name: string extra
+  name: string
+}
+
+type {
+}
+
+GET "/posts" {
+  nme Posts
+  returns
+  returns []Post
+  input {
+    page: [int
+  }
+}
+
+POST /posts {}
+type Last {`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"2:6: Expected `:`, found `int64`",
+		"3:16: Expected a newline, found `extra`",
+		"4:3: Type User already defines `name` at 3:3",
+		"7:6: Expected a type name, found `{`",
+		"11:3: Unknown statement `nme` for GET /posts, expected `name`, `input`, or `returns`",
+		"12:3: `returns` must define a status or a type for GET /posts",
+		"13:3: Type []Post is not defined for the 200 response of GET /posts",
+		"15:12: Expected `]`, found `int`",
+		"19:6: Unexpected character '/'",
+		"19:7: Expected a quoted path, found `posts`",
+		"20:12: Expected `}`, found end of file",
+	}, messages)
+}
+
+func TestParseFileSelectsTheDSLByExtension(t *testing.T) {
+	path := t.TempDir() + "/schema.overtime"
+	require.NoError(t, os.WriteFile(path, []byte("type Post {\n  title: strin\n}\n"), 0o644))
+
+	_, err := ParseFile(path)
+	require.EqualError(t, err, path+":2:3: Type strin is not defined for `title`")
+}
@@ -0,0 +1,101 @@
+package parser
+
+import "strings"
+
+// resolveExtends merges the fields of every type's bases into the type, so
+// the rest of the schema only sees flattened types. Types inherit the fields
+// of their bases in the order the bases are listed, followed by the fields
+// they declare themselves.
+//
+// A type can override an inherited field to change whether it's optional or
+// its doc comment, but not its type. Overrides keep the position of the field
+// they override.
+func (s *Schema) resolveExtends(errs *ErrorList) {
+	resolved := make(map[string]bool, len(s.Types))
+
+	for _, t := range s.SortedTypes() {
+		s.resolveType(t, nil, resolved, errs)
+	}
+}
+
+// resolveType merges the fields of the bases of t into it, resolving the
+// bases first. Stack holds the types currently being resolved, it's used to
+// detect types extending themselves. It returns false when t can't be
+// resolved because of a cycle.
+func (s *Schema) resolveType(t *Type, stack []string, resolved map[string]bool, errs *ErrorList) bool {
+	if resolved[t.Name] {
+		return true
+	}
+
+	for i, name := range stack {
+		if name == t.Name {
+			cycle := append(append([]string{}, stack[i:]...), t.Name)
+			errs.Add(s.Types[name].ExtendsPos, "Type %s extends itself through %s", name, strings.Join(cycle, " -> "))
+
+			return false
+		}
+	}
+
+	if len(t.Extends) == 0 {
+		resolved[t.Name] = true
+		return true
+	}
+
+	stack = append(stack, t.Name)
+	fields := make([]Field, 0, len(t.Fields))
+	positions := make(map[string]int, len(t.Fields))
+
+	for _, baseName := range t.Extends {
+		base, ok := s.Types[baseName]
+		if !ok {
+			errs.Add(t.ExtendsPos, "Type %s extends undefined type %s", t.Name, baseName)
+			continue
+		}
+
+		if !s.resolveType(base, stack, resolved, errs) {
+			continue
+		}
+
+		for _, field := range SortedFields(base.Fields) {
+			if field.InheritedFrom == "" {
+				field.InheritedFrom = base.Name
+			}
+
+			if i, ok := positions[field.Name]; ok {
+				if existing := fields[i]; existing.Type != field.Type {
+					errs.Add(t.ExtendsPos, "Type %s inherits `%s` as %s from %s and as %s from %s", t.Name, field.Name, existing.Type, existing.InheritedFrom, field.Type, field.InheritedFrom)
+				}
+
+				continue
+			}
+
+			positions[field.Name] = len(fields)
+			fields = append(fields, field)
+		}
+	}
+
+	for _, field := range SortedFields(t.Fields) {
+		i, ok := positions[field.Name]
+		if !ok {
+			positions[field.Name] = len(fields)
+			fields = append(fields, field)
+			continue
+		}
+
+		if inherited := fields[i]; inherited.Type != field.Type {
+			errs.Add(field.Pos, "Field `%s` of type %s must be of type %s to override the field inherited from %s", field.Name, t.Name, inherited.Type, inherited.InheritedFrom)
+		}
+
+		fields[i] = field
+	}
+
+	t.Fields = make(map[string]Field, len(fields))
+	for i, field := range fields {
+		field.Index = i
+		t.Fields[field.Name] = field
+	}
+
+	resolved[t.Name] = true
+
+	return true
+}
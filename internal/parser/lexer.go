@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type (
+	tokenKind int
+
+	// token is a single lexical token of an `.overtime` schema. Doc holds the
+	// `//` comment lines directly preceding the token.
+	token struct {
+		kind  tokenKind
+		value string
+		pos   Pos
+		doc   string
+	}
+
+	// lexer splits an `.overtime` schema into tokens, recording invalid
+	// input as errors instead of stopping at the first one.
+	lexer struct {
+		filename string
+		src      []rune
+		offset   int
+		line     int
+		column   int
+		doc      []string
+		// blank is true while the current line has no tokens or comments.
+		blank bool
+		// trailing is true once the current line has a token, so comments
+		// following it are not doc comments.
+		trailing bool
+		errs     *ErrorList
+	}
+)
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenIdent
+	tokenString
+	tokenInt
+	tokenLBrace
+	tokenRBrace
+	tokenLBracket
+	tokenRBracket
+	tokenColon
+	tokenQuestion
+	tokenComma
+)
+
+var punctuation = map[rune]tokenKind{
+	'{': tokenLBrace,
+	'}': tokenRBrace,
+	'[': tokenLBracket,
+	']': tokenRBracket,
+	':': tokenColon,
+	'?': tokenQuestion,
+	',': tokenComma,
+}
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of file"
+	case tokenNewline:
+		return "newline"
+	case tokenIdent:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenInt:
+		return "number"
+	case tokenLBrace:
+		return "`{`"
+	case tokenRBrace:
+		return "`}`"
+	case tokenLBracket:
+		return "`[`"
+	case tokenRBracket:
+		return "`]`"
+	case tokenColon:
+		return "`:`"
+	case tokenQuestion:
+		return "`?`"
+	case tokenComma:
+		return "`,`"
+	default:
+		return "unknown token"
+	}
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenIdent, tokenInt:
+		return fmt.Sprintf("`%s`", t.value)
+	case tokenString:
+		return fmt.Sprintf("%q", t.value)
+	default:
+		return t.kind.String()
+	}
+}
+
+func newLexer(filename string, src string, errs *ErrorList) *lexer {
+	return &lexer{filename: filename, src: []rune(src), line: 1, column: 1, blank: true, errs: errs}
+}
+
+// tokens returns every token of the source, ending with tokenEOF.
+func (l *lexer) tokens() []token {
+	tokens := make([]token, 0)
+	for {
+		t := l.next()
+		tokens = append(tokens, t)
+
+		if t.kind == tokenEOF {
+			return tokens
+		}
+	}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Filename: l.filename, Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peek() rune {
+	if l.offset >= len(l.src) {
+		return 0
+	}
+
+	return l.src[l.offset]
+}
+
+func (l *lexer) advance() rune {
+	r := l.src[l.offset]
+	l.offset++
+
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
+	return r
+}
+
+// emit returns a token, attaching the pending doc comment to it.
+func (l *lexer) emit(kind tokenKind, value string, pos Pos) token {
+	t := token{kind: kind, value: value, pos: pos}
+
+	if kind != tokenNewline && kind != tokenEOF {
+		t.doc = strings.Join(l.doc, "\n")
+		l.doc = nil
+		l.blank = false
+		l.trailing = true
+	}
+
+	return t
+}
+
+func (l *lexer) next() token {
+	for {
+		for l.offset < len(l.src) && (l.peek() == ' ' || l.peek() == '\t' || l.peek() == '\r') {
+			l.advance()
+		}
+
+		pos := l.pos()
+		if l.offset >= len(l.src) {
+			return l.emit(tokenEOF, "", pos)
+		}
+
+		r := l.peek()
+		switch {
+		case r == '\n':
+			l.advance()
+
+			// A blank line separates a comment from the next declaration.
+			if l.blank {
+				l.doc = nil
+			}
+			l.blank = true
+			l.trailing = false
+
+			return l.emit(tokenNewline, "\n", pos)
+		case r == '/' && l.offset+1 < len(l.src) && l.src[l.offset+1] == '/':
+			l.comment()
+		case r == '"':
+			return l.emit(tokenString, l.string(pos), pos)
+		case unicode.IsDigit(r):
+			return l.emit(tokenInt, l.consume(unicode.IsDigit), pos)
+		case isIdentRune(r):
+			return l.emit(tokenIdent, l.consume(isIdentRune), pos)
+		default:
+			l.advance()
+			if kind, ok := punctuation[r]; ok {
+				return l.emit(kind, string(r), pos)
+			}
+
+			l.errs.Add(pos, "Unexpected character %q", r)
+		}
+	}
+}
+
+// comment records a `//` comment as part of the pending doc comment.
+func (l *lexer) comment() {
+	l.advance()
+	l.advance()
+
+	start := l.offset
+	for l.offset < len(l.src) && l.peek() != '\n' {
+		l.advance()
+	}
+
+	l.blank = false
+	if !l.trailing {
+		l.doc = append(l.doc, strings.TrimSpace(string(l.src[start:l.offset])))
+	}
+}
+
+// string returns the contents of a double quoted string, which can't span
+// multiple lines.
+func (l *lexer) string(pos Pos) string {
+	l.advance()
+
+	value := strings.Builder{}
+	for {
+		if l.offset >= len(l.src) || l.peek() == '\n' {
+			l.errs.Add(pos, "Unterminated string")
+			return value.String()
+		}
+
+		r := l.advance()
+		switch r {
+		case '"':
+			return value.String()
+		case '\\':
+			if l.offset < len(l.src) && l.peek() != '\n' {
+				value.WriteRune(l.advance())
+			}
+		default:
+			value.WriteRune(r)
+		}
+	}
+}
+
+func (l *lexer) consume(match func(rune) bool) string {
+	start := l.offset
+	for l.offset < len(l.src) && match(l.peek()) {
+		l.advance()
+	}
+
+	return string(l.src[start:l.offset])
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// decoder parses a single schema file without validating it.
+	decoder func(filename string, s io.Reader, errs *ErrorList) (*Schema, error)
+
+	// loader parses schema files and the files they import, merging them
+	// into a single schema.
+	loader struct {
+		schema *Schema
+		errs   ErrorList
+		// loaded holds the absolute path of every file parsed so far, so
+		// files imported more than once are only merged once.
+		loaded map[string]bool
+	}
+)
+
+// ParseFiles reads the schemas at paths and the schemas they import, merging
+// them into a single Schema. Paths can be glob patterns, e.g.
+// `schemas/*.yaml`, and types or endpoints defined in more than one file are
+// reported as errors naming both files.
+func ParseFiles(paths ...string) (*Schema, error) {
+	l := newLoader()
+
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid schema pattern %s: %w", pattern, err)
+		}
+
+		// Paths without glob characters are loaded as is, so missing files
+		// are reported instead of silently matching nothing.
+		if len(matches) == 0 {
+			matches = []string{pattern}
+		}
+
+		for _, path := range matches {
+			if err := l.loadFile(path, Pos{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return l.finish()
+}
+
+func newLoader() *loader {
+	return &loader{
+		schema: &Schema{
+			Endpoints: make(map[string]*Endpoint),
+			Types:     make(map[string]*Type),
+			Enums:     make(map[string]*Enum),
+		},
+		loaded: make(map[string]bool),
+	}
+}
+
+// loadFile parses the schema at path, picking the decoder from its
+// extension. When the file is imported, from is the position of the import
+// and problems reading the file are recorded as errors at it. Otherwise they
+// are returned.
+func (l *loader) loadFile(path string, from Pos) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if l.loaded[abs] {
+		return nil
+	}
+	l.loaded[abs] = true
+
+	f, err := os.Open(path)
+	if err != nil {
+		if from.Line == 0 {
+			return err
+		}
+
+		l.errs.Add(from, "Failed to import %s: %s", path, err)
+
+		return nil
+	}
+	defer f.Close()
+
+	decode := decodeYAML
+	if filepath.Ext(path) == DSLExtension {
+		decode = decodeDSL
+	}
+
+	err = l.loadReader(path, f, decode)
+	if err != nil && from.Line != 0 {
+		l.errs.Add(from, "Failed to import %s: %s", path, err)
+		return nil
+	}
+
+	return err
+}
+
+// loadReader parses the schema in s, merges it into the loaded schema, and
+// loads the files it imports relative to filename.
+func (l *loader) loadReader(filename string, s io.Reader, decode decoder) error {
+	schema, err := decode(filename, s, &l.errs)
+	if err != nil {
+		return err
+	}
+
+	l.merge(filename, schema)
+
+	for _, imported := range schema.imports {
+		pattern := imported.Path
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(filename), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			l.errs.Add(imported.Pos, "Invalid import %s: %s", imported.Path, err)
+			continue
+		}
+
+		if len(matches) == 0 {
+			l.errs.Add(imported.Pos, "No schema files match the import %s", imported.Path)
+			continue
+		}
+
+		for _, path := range matches {
+			if err := l.loadFile(path, imported.Pos); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// merge adds the types and endpoints of a single file to the loaded schema,
+// keeping the order they were declared in across files.
+func (l *loader) merge(filename string, schema *Schema) {
+	if filename != "" {
+		l.schema.Files = append(l.schema.Files, filename)
+	}
+
+	if schema.Version != "" {
+		switch {
+		case l.schema.Version == "":
+			l.schema.Version = schema.Version
+			l.schema.VersionPos = schema.VersionPos
+		case l.schema.Version != schema.Version:
+			l.errs.Add(schema.VersionPos, "Schema version %s does not match version %s declared at %s", schema.Version, l.schema.Version, l.schema.VersionPos)
+		}
+	}
+
+	for _, t := range schema.SortedTypes() {
+		if existing, ok := l.schema.Types[t.Name]; ok {
+			l.errs.Add(t.Pos, "Type %s is already defined at %s", t.Name, existing.Pos)
+			continue
+		}
+
+		t.Index = len(l.schema.Types)
+		l.schema.Types[t.Name] = t
+	}
+
+	for _, enum := range schema.SortedEnums() {
+		if existing, ok := l.schema.Enums[enum.Name]; ok {
+			l.errs.Add(enum.Pos, "Enum %s is already defined at %s", enum.Name, existing.Pos)
+			continue
+		}
+
+		enum.Index = len(l.schema.Enums)
+		l.schema.Enums[enum.Name] = enum
+	}
+
+	for _, e := range schema.SortedEndpoints() {
+		if conflict := l.schema.endpointConflict(e); conflict != "" {
+			l.errs.Add(e.Pos, "%s", conflict)
+			continue
+		}
+
+		e.Index = len(l.schema.Endpoints)
+		l.schema.Endpoints[e.Method+" "+e.Path] = e
+	}
+}
+
+// finish resolves and validates the merged schema, returning every problem
+// found while loading it.
+func (l *loader) finish() (*Schema, error) {
+	l.schema.resolveExtends(&l.errs)
+
+	if err := l.schema.Validate(); err != nil {
+		l.errs = append(l.errs, err.(ErrorList)...)
+	}
+
+	if err := l.errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.schema, nil
+}
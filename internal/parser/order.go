@@ -0,0 +1,59 @@
+package parser
+
+import "sort"
+
+// SortedTypes returns the types in declaration order.
+func (s *Schema) SortedTypes() []*Type {
+	return sortByDeclaration(mapValues(s.Types), func(t *Type) (int, string) {
+		return t.Index, t.Name
+	})
+}
+
+// SortedEnums returns the enums in declaration order.
+func (s *Schema) SortedEnums() []*Enum {
+	return sortByDeclaration(mapValues(s.Enums), func(e *Enum) (int, string) {
+		return e.Index, e.Name
+	})
+}
+
+// SortedEndpoints returns the endpoints in declaration order, so problems and
+// duplicates are reported against the first declaration.
+func (s *Schema) SortedEndpoints() []*Endpoint {
+	return sortByDeclaration(mapValues(s.Endpoints), func(e *Endpoint) (int, string) {
+		return e.Index, e.Method + " " + e.Path
+	})
+}
+
+// SortedFields returns the fields of a type, or the arguments of an endpoint,
+// in declaration order.
+func SortedFields(fields map[string]Field) []Field {
+	return sortByDeclaration(mapValues(fields), func(f Field) (int, string) {
+		return f.Index, f.Name
+	})
+}
+
+// sortByDeclaration orders items the way they were declared in the schema,
+// falling back to their names so the order is stable across runs.
+func sortByDeclaration[T any](items []T, key func(T) (int, string)) []T {
+	sort.SliceStable(items, func(i, j int) bool {
+		iIndex, iName := key(items[i])
+		jIndex, jName := key(items[j])
+
+		if iIndex != jIndex {
+			return iIndex < jIndex
+		}
+
+		return iName < jName
+	})
+
+	return items
+}
+
+func mapValues[T any](m map[string]T) []T {
+	values := make([]T, 0, len(m))
+	for _, value := range m {
+		values = append(values, value)
+	}
+
+	return values
+}
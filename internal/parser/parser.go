@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,148 +19,609 @@ type (
 	// responsible for combining the relevant schemas of each service into a
 	// single schema that can be used to generate a gateway.
 	Schema struct {
-		Endpoints map[string]*Endpoint
-		Types     map[string]*Type
+		// Version is the version of the schema format the schema is written
+		// against, it is empty when the schema doesn't declare one.
+		Version    string
+		VersionPos Pos
+		Endpoints  map[string]*Endpoint
+		Types      map[string]*Type
+		Enums      map[string]*Enum
+		// Files are the schema files the schema was parsed from, including
+		// imported ones, in the order they were parsed.
+		Files []string
+
+		imports []schemaImport
+	}
+
+	// schemaImport is a file, or a glob pattern matching files, imported by
+	// a schema file.
+	schemaImport struct {
+		Pos  Pos
+		Path string
+	}
+
+	// Pos is the position of an element in a schema file.
+	Pos struct {
+		Filename string
+		Line     int
+		Column   int
 	}
 
 	// Endpoint represents a single endpoint in the schema. It is composed of
 	// a path, types, and fields.
 	Endpoint struct {
-		Name       string
-		Path       string
-		Method     string
-		Args       map[string]Field
-		Returns    string
+		// Index is the position the endpoint was declared at in the schema.
+		Index  int
+		Pos    Pos
+		Name   string
+		Path   string
+		Method string
+		Args   map[string]Field
+		// Returns is the body of the successful response, it is empty when
+		// the endpoint responds without a body.
+		Returns string
+		// Status is the status of the successful response.
+		Status int
+		// Responses contains every declared response, including the
+		// successful one, ordered by status.
+		Responses  []Response
 		DocComment string
 	}
 
+	// Response represents a single response an endpoint can return. Body is
+	// empty for responses without a body.
+	Response struct {
+		Pos    Pos
+		Status int
+		Body   string
+	}
+
 	// Type represents a single partial in the schema. It is composed of a
 	// name and a list of fields. It is the primary tool to keep consistency
 	// within the schema.
 	Type struct {
+		// Index is the position the type was declared at in the schema.
+		Index      int
+		Pos        Pos
 		Name       string
 		Fields     map[string]Field
 		DocComment string
+		// Extends lists the types whose fields are inherited, in the order
+		// they were declared. Inherited fields are merged into Fields.
+		Extends    []string
+		ExtendsPos Pos
 		// TODO fit in federation pieces here
 	}
 
+	// Enum represents a string type restricted to a list of values, e.g. the
+	// states of a post.
+	Enum struct {
+		// Index is the position the enum was declared at in the schema.
+		Index      int
+		Pos        Pos
+		Name       string
+		Values     []string
+		DocComment string
+	}
+
 	// Field represents a single field in the schema. It is composed of a name
 	// and a type. The type is a string that represents the type of the field.
 	// This is a string because the type could be a scalar, an object, or a
 	// list of objects.
 	Field struct {
+		// Index is the position the field was declared at in its type or
+		// endpoint input.
+		Index      int
+		Pos        Pos
 		Name       string
 		Type       string
 		IsOptional bool
 		IsPartial  bool
 		DocComment string
+		// InheritedFrom is the type a field was inherited from through
+		// `extends`, it is empty for fields declared by the type itself.
+		InheritedFrom string
+		// Source is where an endpoint argument is read from in the request.
+		// It is unused for the fields of a type.
+		Source InputSource
 	}
 
-	// RawSchema is the representation of the raw schema file before converted
-	// into an internal schema
-	rawSchema struct {
-		Types     map[string]rawType     `yaml:"types"`
-		Endpoints map[string]rawEndpoint `yaml:"endpoints"`
-	}
-
-	rawEndpoint struct {
-		Name     string      `yaml:"name"`
-		Response rawResponse `yaml:"response"`
-	}
+	// InputSource represents the part of an incoming request that an
+	// endpoint argument is read from.
+	InputSource int
 
 	rawResponse struct {
 		Status int    `yaml:"status"`
 		Body   string `yaml:"body"`
 	}
 
-	rawType struct {
-		Fields map[string]string `yaml:"fields"`
+	// schemaParser builds a Schema from the YAML node tree, recording the
+	// position of every element so errors can point at the schema source.
+	schemaParser struct {
+		filename string
+		errs     ErrorList
 	}
 )
 
-func (e *Endpoint) Validate() error {
-	if e.Path == "" {
-		return fmt.Errorf("Path is required")
+const (
+	// InputQuery arguments are read from the query string.
+	InputQuery InputSource = iota
+	// InputPath arguments are read from `:name` segments of the path.
+	InputPath
+	// InputBody arguments are read from the JSON request body.
+	InputBody
+)
+
+func (s InputSource) String() string {
+	switch s {
+	case InputPath:
+		return "path"
+	case InputBody:
+		return "body"
+	default:
+		return "query"
+	}
+}
+
+// IsSuccess returns true for 2xx responses.
+func (r Response) IsSuccess() bool {
+	return r.Status >= 200 && r.Status < 300
+}
+
+func (p Pos) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
 	}
 
-	if e.Method == "" {
-		return fmt.Errorf("Method is required")
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+var MethodPathRegex = regexp.MustCompile(`(\w+)\s+(.*)`)
+
+// PathParams returns the names of the `:name` segments of the endpoint path
+// in the order they appear.
+func (e *Endpoint) PathParams() []string {
+	params := make([]string, 0)
+	for _, part := range strings.Split(e.Path, "/") {
+		if strings.HasPrefix(part, ":") {
+			params = append(params, strings.TrimPrefix(part, ":"))
+		}
 	}
 
-	if e.Name == "" {
-		return fmt.Errorf("`name` is not defined for %s %s", e.Method, e.Path)
+	return params
+}
+
+// route returns the method and path of the endpoint with every path parameter
+// replaced by `:`, so routes that only differ in the names of their
+// parameters, which conflict once served, are equal.
+func (e *Endpoint) route() string {
+	parts := strings.Split(e.Path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = ":"
+		}
 	}
 
-	if e.Returns == "" {
-		return fmt.Errorf("`returns` is not defined for %s %s", e.Method, e.Path)
+	return e.Method + " " + strings.Join(parts, "/")
+}
+
+// endpointConflict describes the endpoint already declared in the schema with
+// the same route as e, or returns an empty string when there is none.
+func (s *Schema) endpointConflict(e *Endpoint) string {
+	route := e.route()
+	for _, existing := range s.Endpoints {
+		if existing.route() != route {
+			continue
+		}
+
+		if existing.Path == e.Path {
+			return fmt.Sprintf("Endpoint %s %s is already defined at %s", e.Method, e.Path, existing.Pos)
+		}
+
+		return fmt.Sprintf("Endpoint %s %s conflicts with %s %s defined at %s", e.Method, e.Path, existing.Method, existing.Path, existing.Pos)
 	}
 
-	return nil
+	return ""
 }
 
-var MethodPathRegex = regexp.MustCompile(`(\w+)\s+(.*)`)
+// Capitalize upper cases the first letter of s, turning a schema name into the
+// exported identifier generated for it.
+func Capitalize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	r := []rune(s)
 
+	return string(append([]rune{unicode.ToUpper(r[0])}, r[1:]...))
+}
+
+// FieldName returns the name of the struct field generated for a field or
+// input, e.g. `ID` for `id`.
+func FieldName(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+
+	return Capitalize(name)
+}
+
+// newField returns a field for the given schema key, stripping the `?` suffix
+// used to mark optional fields.
+func newField(name string, fieldType string) Field {
+	return Field{
+		Name:       strings.TrimSuffix(name, "?"),
+		Type:       fieldType,
+		IsOptional: strings.HasSuffix(name, "?"),
+	}
+}
+
+// inputSource returns where an endpoint argument is read from. Arguments
+// matching a path segment come from the path, the remaining arguments come
+// from the query string for methods without a body and from the JSON body
+// otherwise.
+func inputSource(method string, name string, pathParams []string) InputSource {
+	for _, param := range pathParams {
+		if param == name {
+			return InputPath
+		}
+	}
+
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "DELETE", "OPTIONS":
+		return InputQuery
+	default:
+		return InputBody
+	}
+}
+
+// Parse reads a YAML schema from s. Imports are resolved relative to the
+// working directory. Every problem found while parsing and validating the
+// schema is returned as an ErrorList.
 func Parse(s io.Reader) (*Schema, error) {
-	root := rawSchema{}
-	err := yaml.NewDecoder(s).Decode(&root)
+	l := newLoader()
+	if err := l.loadReader("", s, decodeYAML); err != nil {
+		return nil, err
+	}
+
+	return l.finish()
+}
+
+// ParseFile reads the schema at filename and the schemas it imports, using
+// the filename in the positions of the parsed schema. Files with the
+// `.overtime` extension are parsed as the overtime DSL, any other file as
+// YAML.
+func ParseFile(filename string) (*Schema, error) {
+	l := newLoader()
+	if err := l.loadFile(filename, Pos{}); err != nil {
+		return nil, err
+	}
+
+	return l.finish()
+}
+
+// decodeYAML parses a YAML schema without validating it, recording every
+// problem found in errs. An error is only returned when s is not valid YAML.
+func decodeYAML(filename string, s io.Reader, errs *ErrorList) (*Schema, error) {
+	document := yaml.Node{}
+	err := yaml.NewDecoder(s).Decode(&document)
 	if err != nil {
 		return nil, err
 	}
 
+	p := &schemaParser{filename: filename}
+	schema := p.parseSchema(&document)
+	*errs = append(*errs, p.errs...)
+
+	return schema, nil
+}
+
+func (p *schemaParser) pos(node *yaml.Node) Pos {
+	return Pos{Filename: p.filename, Line: node.Line, Column: node.Column}
+}
+
+func (p *schemaParser) errorf(node *yaml.Node, format string, args ...any) {
+	p.errs.Add(p.pos(node), format, args...)
+}
+
+// mapping returns the key and value nodes of a mapping node, in declaration
+// order. An error is recorded and no pairs are returned when node is not a
+// mapping.
+func (p *schemaParser) mapping(node *yaml.Node, name string) [][2]*yaml.Node {
+	if node == nil || (node.Kind == yaml.ScalarNode && node.Tag == "!!null") {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		p.errorf(node, "`%s` must be a mapping", name)
+		return nil
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+
+	return pairs
+}
+
+// lookup returns the value of key in a mapping node, or nil when it is not
+// present.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func (p *schemaParser) parseSchema(document *yaml.Node) *Schema {
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	p.mapping(root, "schema")
+	rawTypes := p.mapping(lookup(root, "types"), "types")
+	rawEndpoints := p.mapping(lookup(root, "endpoints"), "endpoints")
+	rawEnums := p.mapping(lookup(root, "enums"), "enums")
+
 	schema := &Schema{
-		Endpoints: make(map[string]*Endpoint, len(root.Endpoints)),
-		Types:     make(map[string]*Type, len(root.Types)),
+		Endpoints: make(map[string]*Endpoint, len(rawEndpoints)),
+		Types:     make(map[string]*Type, len(rawTypes)),
+		Enums:     make(map[string]*Enum, len(rawEnums)),
 	}
 
-	for name, rawType := range root.Types {
-		t := &Type{
-			Name:   name,
-			Fields: make(map[string]Field, len(rawType.Fields)),
+	if version := lookup(root, "version"); version != nil {
+		schema.VersionPos = p.pos(version)
+		if version.Kind != yaml.ScalarNode {
+			p.errorf(version, "`version` must be a version like %s", Version)
+		} else {
+			schema.Version = version.Value
 		}
+	}
 
-		for fieldName, fieldType := range rawType.Fields {
-			t.Fields[fieldName] = Field{
-				Name: fieldName,
-				Type: fieldType,
+	if imports := lookup(root, "imports"); imports != nil {
+		switch imports.Kind {
+		case yaml.ScalarNode:
+			schema.imports = append(schema.imports, schemaImport{Pos: p.pos(imports), Path: imports.Value})
+		case yaml.SequenceNode:
+			for _, imported := range imports.Content {
+				if imported.Kind != yaml.ScalarNode {
+					p.errorf(imported, "`imports` must be a file or a list of files")
+					continue
+				}
+
+				schema.imports = append(schema.imports, schemaImport{Pos: p.pos(imported), Path: imported.Value})
 			}
+		default:
+			p.errorf(imports, "`imports` must be a file or a list of files")
+		}
+	}
+
+	for i, pair := range rawTypes {
+		t := p.parseType(i, pair[0], pair[1])
+
+		if existing, ok := schema.Types[t.Name]; ok {
+			p.errorf(pair[0], "Type %s is already defined at %s", t.Name, existing.Pos)
+			continue
 		}
 
-		schema.Types[name] = t
+		schema.Types[t.Name] = t
 	}
 
-	for rawPath, rawEndpoint := range root.Endpoints {
-		matches := MethodPathRegex.FindStringSubmatch(rawPath)
+	for i, pair := range rawEnums {
+		enum := p.parseEnum(i, pair[0], pair[1])
+		if enum == nil {
+			continue
+		}
 
-		if len(matches) != 3 {
-			return nil, fmt.Errorf("Invalid path: %s, needs format `<HTTP_VERB> <PATH>`", rawPath)
+		if existing, ok := schema.Enums[enum.Name]; ok {
+			p.errorf(pair[0], "Enum %s is already defined at %s", enum.Name, existing.Pos)
+			continue
 		}
 
-		method := matches[1]
-		path := matches[2]
+		schema.Enums[enum.Name] = enum
+	}
 
-		e := &Endpoint{
-			Name:    rawEndpoint.Name,
-			Method:  method,
-			Path:    path,
-			Args:    make(map[string]Field, len(rawEndpoint.Response.Body)),
-			Returns: rawEndpoint.Response.Body,
+	for i, pair := range rawEndpoints {
+		e := p.parseEndpoint(i, pair[0], pair[1])
+		if e == nil {
+			continue
 		}
 
-		if e.Name == "" {
-			panic(fmt.Sprintf("`name` is not defined for %s", path))
+		if conflict := schema.endpointConflict(e); conflict != "" {
+			p.errorf(pair[0], "%s", conflict)
+			continue
 		}
 
-		normalizedType := strings.TrimPrefix(e.Returns, "[]")
-		if _, ok := root.Types[normalizedType]; !ok {
-			panic(fmt.Sprintf("Type %s is not defined for %s", normalizedType, path))
+		schema.Endpoints[e.Method+" "+e.Path] = e
+	}
+
+	return schema
+}
+
+func (p *schemaParser) parseType(index int, key *yaml.Node, value *yaml.Node) *Type {
+	rawFields := p.mapping(lookup(value, "fields"), "fields")
+
+	t := &Type{
+		Index:  index,
+		Pos:    p.pos(key),
+		Name:   key.Value,
+		Fields: make(map[string]Field, len(rawFields)),
+	}
+
+	p.parseFields(rawFields, t.Fields, "Type "+t.Name)
+
+	if extends := lookup(value, "extends"); extends != nil {
+		t.ExtendsPos = p.pos(extends)
+		switch extends.Kind {
+		case yaml.ScalarNode:
+			t.Extends = []string{extends.Value}
+		case yaml.SequenceNode:
+			for _, base := range extends.Content {
+				if base.Kind != yaml.ScalarNode {
+					p.errorf(base, "`extends` of type %s must be a type or a list of types", t.Name)
+					continue
+				}
+
+				t.Extends = append(t.Extends, base.Value)
+			}
+		default:
+			p.errorf(extends, "`extends` of type %s must be a type or a list of types", t.Name)
 		}
+	}
+
+	return t
+}
+
+// parseEnum parses an enum declared as a list of values, e.g.
+// `PostState: [draft, published]`. It returns nil when the enum isn't a list.
+func (p *schemaParser) parseEnum(index int, key *yaml.Node, value *yaml.Node) *Enum {
+	enum := &Enum{Index: index, Pos: p.pos(key), Name: key.Value}
+
+	if value.Kind != yaml.SequenceNode {
+		p.errorf(value, "Enum %s must be a list of values", enum.Name)
+		return nil
+	}
 
-		if e.Returns == "" {
-			panic(fmt.Sprintf("`returns` is not defined for %s", path))
+	for _, v := range value.Content {
+		if v.Kind != yaml.ScalarNode {
+			p.errorf(v, "The values of enum %s must be strings", enum.Name)
+			continue
 		}
 
-		schema.Endpoints[path] = e
+		enum.Values = append(enum.Values, v.Value)
 	}
 
-	return schema, nil
+	return enum
+}
+
+// parseFields adds the fields declared by pairs to fields, recording an
+// error for fields declared more than once by owner.
+func (p *schemaParser) parseFields(pairs [][2]*yaml.Node, fields map[string]Field, owner string) {
+	for i, pair := range pairs {
+		key, value := pair[0], pair[1]
+		if value.Kind != yaml.ScalarNode {
+			p.errorf(value, "The type of `%s` must be a string", key.Value)
+			continue
+		}
+
+		field := newField(key.Value, value.Value)
+		field.Index = i
+		field.Pos = p.pos(key)
+
+		if existing, ok := fields[field.Name]; ok {
+			p.errorf(key, "%s already defines `%s` at %s", owner, field.Name, existing.Pos)
+			continue
+		}
+
+		fields[field.Name] = field
+	}
+}
+
+func (p *schemaParser) parseEndpoint(index int, key *yaml.Node, value *yaml.Node) *Endpoint {
+	rawPath := key.Value
+	matches := MethodPathRegex.FindStringSubmatch(rawPath)
+
+	if len(matches) != 3 {
+		p.errorf(key, "Invalid path: %s, needs format `<HTTP_VERB> <PATH>`", rawPath)
+		return nil
+	}
+
+	method := matches[1]
+	path := matches[2]
+	rawInput := p.mapping(lookup(value, "input"), "input")
+
+	e := &Endpoint{
+		Index:     index,
+		Pos:       p.pos(key),
+		Method:    method,
+		Path:      path,
+		Args:      make(map[string]Field, len(rawInput)),
+		Responses: p.parseResponses(value, rawPath),
+	}
+
+	if name := lookup(value, "name"); name != nil {
+		e.Name = name.Value
+	}
+
+	p.parseFields(rawInput, e.Args, "The input of "+rawPath)
+	e.resolve()
+
+	return e
+}
+
+// resolve fills in the successful response and the source of every argument
+// once the responses and arguments of the endpoint are parsed.
+func (e *Endpoint) resolve() {
+	for _, response := range e.Responses {
+		if response.IsSuccess() {
+			e.Status = response.Status
+			e.Returns = response.Body
+		}
+	}
+
+	pathParams := e.PathParams()
+	for name, arg := range e.Args {
+		arg.Source = inputSource(e.Method, arg.Name, pathParams)
+		e.Args[name] = arg
+	}
+}
+
+// parseResponses returns every response declared by the endpoint ordered by
+// status. Endpoints declare either a single `response` or a `responses` map
+// of status to body.
+func (p *schemaParser) parseResponses(endpoint *yaml.Node, rawPath string) []Response {
+	single := lookup(endpoint, "response")
+	multiple := lookup(endpoint, "responses")
+
+	if single != nil && multiple != nil {
+		p.errorf(multiple, "Only one of `response` or `responses` can be defined for %s", rawPath)
+		return nil
+	}
+
+	if single != nil {
+		raw := rawResponse{}
+		if err := single.Decode(&raw); err != nil {
+			p.errorf(single, "Invalid response for %s: %s", rawPath, err)
+			return nil
+		}
+
+		if raw.Status == 0 && raw.Body == "" {
+			p.errorf(single, "`response` must define a `body` or a `status` for %s", rawPath)
+			return nil
+		}
+
+		status := raw.Status
+		if status == 0 {
+			status = 200
+		}
+
+		return []Response{{Pos: p.pos(single), Status: status, Body: raw.Body}}
+	}
+
+	pairs := p.mapping(multiple, "responses")
+	responses := make([]Response, 0, len(pairs))
+	for _, pair := range pairs {
+		status, err := strconv.Atoi(pair[0].Value)
+		if err != nil {
+			p.errorf(pair[0], "Invalid status %s for %s", pair[0].Value, rawPath)
+			continue
+		}
+
+		responses = append(responses, Response{Pos: p.pos(pair[0]), Status: status, Body: pair[1].Value})
+	}
+
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].Status < responses[j].Status
+	})
+
+	return responses
 }
@@ -0,0 +1,571 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreservesDeclarationOrderAndPositions(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`
+types:
+  Post:
+    fields:
+      title: string
+      id: int64
+  Comment:
+    fields:
+      id: int64
+endpoints:
+  "GET /posts/:postID":
+    name: GetPost
+    input:
+      postID: int64
+      page?: int
+    response:
+      body: Post`))
+	require.NoError(t, err)
+
+	post := schema.Types["Post"]
+	require.Equal(t, 0, post.Index)
+	require.Equal(t, Pos{Line: 3, Column: 3}, post.Pos)
+	require.Equal(t, 1, schema.Types["Comment"].Index)
+
+	require.Equal(t, 0, post.Fields["title"].Index)
+	require.Equal(t, 1, post.Fields["id"].Index)
+	require.Equal(t, Pos{Line: 6, Column: 7}, post.Fields["id"].Pos)
+
+	endpoint := schema.Endpoints["GET /posts/:postID"]
+	require.Equal(t, Pos{Line: 11, Column: 3}, endpoint.Pos)
+	require.Equal(t, 1, endpoint.Args["page"].Index)
+	require.True(t, endpoint.Args["page"].IsOptional)
+	require.Equal(t, InputPath, endpoint.Args["postID"].Source)
+	require.Equal(t, InputQuery, endpoint.Args["page"].Source)
+}
+
+func TestParseRequiresIDForResolvers(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+types:
+    User:
+        fields:
+            name: string
+    Post:
+        fields:
+            author: User
+endpoints:
+    "GET /api/v1/posts":
+        name: ListPosts
+        response:
+            body: "[]Post"`))
+
+	require.EqualError(t, err, "8:13: Type Post must define an `id` field to resolve its `author` field")
+}
+
+func TestParseReportsEveryProblem(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+types:
+  Post:
+    fields:
+      id: int64
+      author: User
+      bad-name: string
+endpoints:
+  "GET /posts/:postID":
+    name: GetPost
+    response:
+      body: Article
+  "GET /posts":
+    name: GetPost
+    input:
+      page?: "[]int"
+    response:
+      body: "[]Post"
+  "POST /posts":
+    input:
+      title: string
+    responses:
+      201: Post
+      404: "[]Post"`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"6:7: Type User is not defined for `author`",
+		"7:7: Field name `bad-name` is not a valid identifier",
+		"9:3: Path parameter `postID` is not defined in `input` for GET /posts/:postID",
+		"12:7: Type Article is not defined for the 200 response of GET /posts/:postID",
+		"13:3: Endpoint name `GetPost` is already used by GET /posts/:postID at 9:3",
+		"16:7: The query parameter `page` of GET /posts must be a scalar, got []int",
+		"19:3: `name` is not defined for POST /posts",
+		"24:7: The 404 response of POST /posts must be a single type, got []Post",
+	}, messages)
+}
+
+func TestParseFileIncludesFilenameInErrors(t *testing.T) {
+	path := t.TempDir() + "/schema.yaml"
+	require.NoError(t, os.WriteFile(path, []byte(`
+types:
+  Post:
+    fields:
+      id: int64
+      title: strin
+`), 0o644))
+
+	_, err := ParseFile(path)
+	require.EqualError(t, err, path+":6:7: Type strin is not defined for `title`")
+}
+
+func TestParseMergesExtendedFields(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`
+types:
+  Timestamps:
+    fields:
+      createdAt: string
+      updatedAt?: string
+  Comment:
+    fields:
+      id: int64
+      body: string
+  DetailedComment:
+    extends: [Comment, Timestamps]
+    fields:
+      updatedAt: string
+      edits: int
+endpoints:
+  "GET /comments/:commentID":
+    name: GetComment
+    input:
+      commentID: int64
+    response:
+      body: DetailedComment`))
+	require.NoError(t, err)
+
+	detailed := schema.Types["DetailedComment"]
+	require.Equal(t, []string{"Comment", "Timestamps"}, detailed.Extends)
+	require.Equal(t, Pos{Line: 12, Column: 14}, detailed.ExtendsPos)
+
+	names := make([]string, len(detailed.Fields))
+	for name, field := range detailed.Fields {
+		names[field.Index] = name
+	}
+	require.Equal(t, []string{"id", "body", "createdAt", "updatedAt", "edits"}, names)
+
+	require.Equal(t, "Comment", detailed.Fields["id"].InheritedFrom)
+	require.Equal(t, "Timestamps", detailed.Fields["createdAt"].InheritedFrom)
+	require.Equal(t, "", detailed.Fields["updatedAt"].InheritedFrom)
+	require.False(t, detailed.Fields["updatedAt"].IsOptional)
+	require.Equal(t, Pos{Line: 14, Column: 7}, detailed.Fields["updatedAt"].Pos)
+
+	require.Len(t, schema.Types["Comment"].Fields, 2)
+}
+
+func TestParseReportsInvalidExtends(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+types:
+  A:
+    extends: B
+  B:
+    extends: [A]
+  Base:
+    fields:
+      id: int64
+  Other:
+    fields:
+      id: string
+  Child:
+    extends: [Base, Other, Missing]
+    fields:
+      id: bool
+endpoints:
+  "GET /a":
+    name: GetA
+    response:
+      body: A`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"4:14: Type A extends itself through A -> B -> A",
+		"14:14: Type Child inherits `id` as int64 from Base and as string from Other",
+		"14:14: Type Child extends undefined type Missing",
+		"16:7: Field `id` of type Child must be of type int64 to override the field inherited from Base",
+	}, messages)
+}
+
+func TestCheckVersion(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`
+version: 0.0.1
+types:
+  Post:
+    fields:
+      id: int64`))
+	require.NoError(t, err)
+	require.Equal(t, "0.0.1", schema.Version)
+	require.Equal(t, Pos{Line: 2, Column: 10}, schema.VersionPos)
+	require.NoError(t, schema.CheckVersion())
+
+	schema.Version = ""
+	require.NoError(t, schema.CheckVersion())
+
+	schema.Version = "99.0.0"
+	require.EqualError(t, schema.CheckVersion(), "2:10: Schema version 99.0.0 is newer than this version of overtime supports ("+Version+"). Upgrade overtime to use it")
+
+	_, err = Parse(strings.NewReader(`version: 1.x`))
+	require.EqualError(t, err, "1:10: Invalid schema version `1.x`, expected a version like "+Version)
+}
+
+func TestParseFilesMergesImports(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(dir+"/users", 0o755))
+	require.NoError(t, os.WriteFile(dir+"/schema.yaml", []byte(`
+imports:
+  - users/*.yaml
+  - posts.overtime
+types:
+  Comment:
+    fields:
+      id: int64
+      author: User
+`), 0o644))
+	require.NoError(t, os.WriteFile(dir+"/users/user.yaml", []byte(`
+types:
+  User:
+    fields:
+      id: int64
+`), 0o644))
+	require.NoError(t, os.WriteFile(dir+"/posts.overtime", []byte(`import "schema.yaml"
+
+type Post {
+  id: int64
+  comments: []Comment
+}
+
+GET "/posts" {
+  name ListPosts
+  returns []Post
+}
+`), 0o644))
+
+	schema, err := ParseFile(dir + "/schema.yaml")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{dir + "/schema.yaml", dir + "/users/user.yaml", dir + "/posts.overtime"}, schema.Files)
+	require.Equal(t, 0, schema.Types["Comment"].Index)
+	require.Equal(t, 1, schema.Types["User"].Index)
+	require.Equal(t, 2, schema.Types["Post"].Index)
+	require.Equal(t, Pos{Filename: dir + "/posts.overtime", Line: 3, Column: 6}, schema.Types["Post"].Pos)
+	require.Contains(t, schema.Endpoints, "GET /posts")
+
+	merged, err := ParseFiles(dir+"/users/*.yaml", dir+"/posts.overtime")
+	require.NoError(t, err)
+	require.Len(t, merged.Types, 3)
+}
+
+func TestParseFilesReportsDuplicatesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/a.yaml", []byte(`
+imports: [b.yaml, missing/*.yaml]
+types:
+  Post:
+    fields:
+      id: int64
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    response:
+      body: "[]Post"
+`), 0o644))
+	require.NoError(t, os.WriteFile(dir+"/b.yaml", []byte(`
+types:
+  Post:
+    fields:
+      id: string
+endpoints:
+  "GET /posts":
+    name: AllPosts
+    response:
+      body: "[]Post"
+`), 0o644))
+
+	_, err := ParseFile(dir + "/a.yaml")
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		dir + "/a.yaml:2:19: No schema files match the import missing/*.yaml",
+		dir + "/b.yaml:3:3: Type Post is already defined at " + dir + "/a.yaml:4:3",
+		dir + "/b.yaml:7:3: Endpoint GET /posts is already defined at " + dir + "/a.yaml:8:3",
+	}, messages)
+}
+
+func TestParseReportsConflictingRoutes(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+types:
+  Post:
+    fields:
+      id: int64
+endpoints:
+  "GET /posts/:id":
+    name: GetPost
+    input:
+      id: int64
+    response:
+      body: Post
+  "GET /posts/:postID":
+    name: ShowPost
+    input:
+      postID: int64
+    response:
+      body: Post
+  "GET /_overtime/schema":
+    name: Schema
+    response:
+      body: Post`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"13:3: Endpoint GET /posts/:postID conflicts with GET /posts/:id defined at 7:3",
+		"19:3: Endpoint GET /_overtime/schema conflicts with the schema endpoint served by the generated code",
+	}, messages)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/a.yaml", []byte(`
+imports: [b.yaml]
+types:
+  Post:
+    fields:
+      id: int64
+endpoints:
+  "DELETE /posts/:id":
+    name: DeletePost
+    input:
+      id: int64
+    response:
+      status: 204
+`), 0o644))
+	require.NoError(t, os.WriteFile(dir+"/b.yaml", []byte(`
+endpoints:
+  "DELETE /posts/:postID":
+    name: RemovePost
+    input:
+      postID: int64
+    response:
+      status: 204
+`), 0o644))
+
+	_, err = ParseFile(dir + "/a.yaml")
+	require.EqualError(t, err, dir+"/b.yaml:3:3: Endpoint DELETE /posts/:postID conflicts with DELETE /posts/:id defined at "+dir+"/a.yaml:8:3")
+}
+
+func TestParseEnums(t *testing.T) {
+	schema, err := Parse(strings.NewReader(`
+enums:
+  Visibility: [public, private]
+  PostState:
+    - draft
+    - in_review
+types:
+  Post:
+    fields:
+      id: int64
+      state: PostState
+      history: "[]PostState"
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    input:
+      state?: PostState
+      visibility: Visibility
+    response:
+      body: "[]Post"`))
+	require.NoError(t, err)
+
+	state := schema.Enums["PostState"]
+	require.Equal(t, 1, state.Index)
+	require.Equal(t, Pos{Line: 4, Column: 3}, state.Pos)
+	require.Equal(t, []string{"draft", "in_review"}, state.Values)
+	require.True(t, schema.IsScalar("PostState"))
+	require.True(t, schema.IsScalar("int64"))
+	require.False(t, schema.IsScalar("Post"))
+}
+
+func TestParseReportsInvalidEnums(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+enums:
+  Empty: []
+  string: [a]
+  Post: [a]
+  State: [draft, draft, in-review, InReview, in_review]
+  Nested: {a: b}
+types:
+  Post:
+    fields:
+      id: int64
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    input:
+      state: State
+    response:
+      body: "[]Post"`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"3:3: Enum Empty must define at least one value",
+		"4:3: Enum name `string` conflicts with a builtin type",
+		"5:3: Enum Post conflicts with the type declared at 9:3",
+		"6:3: Enum State defines the value `draft` more than once",
+		"6:3: Value `in-review` of enum State is not a valid identifier",
+		"6:3: Value `in_review` of enum State conflicts with `InReview`",
+		"7:11: Enum Nested must be a list of values",
+	}, messages)
+}
+
+func TestParseReportsConflictingGoNames(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+enums:
+  state: [draft]
+types:
+  post:
+    fields:
+      id: int64
+  State:
+    fields:
+      id: int64
+  Base:
+    fields:
+      name: string
+  User:
+    extends: Base
+    fields:
+      id: int64
+      ID: string
+      Name: string
+endpoints:
+  "GET /users":
+    name: ListUsers
+    input:
+      page?: int
+      Page?: int
+    response:
+      body: "[]User"`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"3:3: Enum state conflicts with the type declared at 8:3",
+		"5:3: Type name `post` must start with an uppercase letter",
+		"18:7: Field `ID` of type User conflicts with `id`, both are generated as ID",
+		"19:7: Field `Name` of type User conflicts with `name`, both are generated as Name",
+		"25:7: Field `Page` of the input of GET /users conflicts with `page`, both are generated as Page",
+	}, messages)
+}
+
+func TestParseReportsErrorFieldsOfErrorResponses(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+types:
+  Post:
+    fields:
+      id: int64
+  ValidationError:
+    fields:
+      error: string
+endpoints:
+  "POST /posts":
+    name: CreatePost
+    responses:
+      201: Post
+      422: ValidationError`))
+
+	require.EqualError(t, err, "14:7: Type ValidationError can not be used by the 422 response of POST /posts because its `error` field conflicts with the generated Error method")
+}
+
+func TestParseReportsNamesReservedByTheGeneratedCode(t *testing.T) {
+	_, err := Parse(strings.NewReader(`
+enums:
+  Conflict: [a]
+types:
+  NotFound:
+    fields:
+      message: string
+  errorResponse:
+    fields:
+      message: string
+  Post:
+    fields:
+      id: int64
+  ResolveForPost:
+    fields:
+      id: int64
+  ListPostsInput:
+    fields:
+      page: int
+endpoints:
+  "GET /posts":
+    name: ListPosts
+    input:
+      page?: int
+    response:
+      body: "[]Post"
+  "GET /drafts":
+    name: ListDrafts
+    response:
+      body: "[]Post"`))
+
+	var errs ErrorList
+	require.ErrorAs(t, err, &errs)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	require.Equal(t, []string{
+		"3:3: Enum name `Conflict` is reserved by the generated code",
+		"5:3: Type name `NotFound` is reserved by the generated code",
+		"8:3: Type name `errorResponse` must start with an uppercase letter",
+		"8:3: Type name `errorResponse` is reserved by the generated code",
+		"14:3: Type name `ResolveForPost` conflicts with the resolver function generated for type Post",
+		"21:3: The input of endpoint ListPosts is generated as ListPostsInput, which conflicts with the declaration at 17:3",
+	}, messages)
+}
@@ -0,0 +1,453 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	// Error is a single problem found in a schema.
+	Error struct {
+		Pos     Pos
+		Message string
+	}
+
+	// ErrorList contains every problem found in a schema, so they can be
+	// reported at once instead of one at a time.
+	ErrorList []*Error
+)
+
+// Builtins are the scalar types that can be used in a schema without being
+// declared.
+var Builtins = map[string]bool{
+	"int":     true,
+	"int64":   true,
+	"string":  true,
+	"bool":    true,
+	"float":   true,
+	"float64": true,
+}
+
+var methods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
+// generatedNames are the identifiers declared by every generated package.
+// Types and enums are generated into the same package, so they can't use
+// them.
+var generatedNames = map[string]bool{
+	"Coordinator":         true,
+	"CoordinatorOption":   true,
+	"NewCoordinator":      true,
+	"WithErrorHandler":    true,
+	"WithMaxConcurrency":  true,
+	"WithSchemaEndpoint":  true,
+	"RequestFromContext":  true,
+	"SchemaVersion":       true,
+	"Controller":          true,
+	"Resolver":            true,
+	"RootController":      true,
+	"RootResolver":        true,
+	"HTTPError":           true,
+	"NewHTTPError":        true,
+	"NotFound":            true,
+	"Unauthorized":        true,
+	"Forbidden":           true,
+	"Conflict":            true,
+	"NotImplemented":      true,
+	"ErrorHandler":        true,
+	"ErrorHandlerFunc":    true,
+	"DefaultErrorHandler": true,
+	"ErrorResponse":       true,
+	"ErrorDetails":        true,
+	"InvalidField":        true,
+	"InputError":          true,
+}
+
+// schemaEndpointRoute is the route the generated code serves the schema from.
+const schemaEndpointRoute = "GET /_overtime/schema"
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// Add records a problem at pos.
+func (l *ErrorList) Add(pos Pos, format string, args ...any) {
+	*l = append(*l, &Error{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// Sort orders the errors by their position in the schema.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Pos, l[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+}
+
+// Err returns the sorted list as an error, or nil when it is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+
+	l.Sort()
+
+	return l
+}
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks the schema for semantic problems, like references to
+// undefined types or endpoints missing path parameters, returning every
+// problem found as an ErrorList.
+func (s *Schema) Validate() error {
+	errs := ErrorList{}
+
+	if _, ok := parseVersion(s.Version); s.Version != "" && !ok {
+		errs.Add(s.VersionPos, "Invalid schema version `%s`, expected a version like %s", s.Version, Version)
+	}
+
+	for _, t := range s.Types {
+		s.validateType(t, &errs)
+	}
+
+	for _, enum := range s.SortedEnums() {
+		s.validateEnum(enum, &errs)
+	}
+
+	names := make(map[string]*Endpoint, len(s.Endpoints))
+	for _, e := range s.SortedEndpoints() {
+		s.validateEndpoint(e, &errs)
+
+		if e.Name == "" {
+			continue
+		}
+
+		name := Capitalize(e.Name)
+		if existing, ok := names[name]; ok {
+			errs.Add(e.Pos, "Endpoint name `%s` is already used by %s %s at %s", e.Name, existing.Method, existing.Path, existing.Pos)
+			continue
+		}
+
+		names[name] = e
+	}
+
+	return errs.Err()
+}
+
+func (s *Schema) validateEnum(enum *Enum, errs *ErrorList) {
+	if !identifierRegex.MatchString(enum.Name) {
+		errs.Add(enum.Pos, "Enum name `%s` is not a valid identifier", enum.Name)
+	}
+
+	if Builtins[enum.Name] {
+		errs.Add(enum.Pos, "Enum name `%s` conflicts with a builtin type", enum.Name)
+	}
+
+	s.validateGeneratedName("Enum", enum.Name, enum.Pos, errs)
+
+	// Enums are generated with a capitalized name, so `state` conflicts
+	// with a `State` type.
+	if t, ok := s.Types[Capitalize(enum.Name)]; ok {
+		errs.Add(enum.Pos, "Enum %s conflicts with the type declared at %s", enum.Name, t.Pos)
+	}
+
+	if len(enum.Values) == 0 {
+		errs.Add(enum.Pos, "Enum %s must define at least one value", enum.Name)
+	}
+
+	// Values are used in the names of the generated constants, so values
+	// only differing by case or underscores would produce the same name.
+	seen := make(map[string]string, len(enum.Values))
+	for _, value := range enum.Values {
+		if !identifierRegex.MatchString(value) {
+			errs.Add(enum.Pos, "Value `%s` of enum %s is not a valid identifier", value, enum.Name)
+			continue
+		}
+
+		key := strings.ToLower(strings.ReplaceAll(value, "_", ""))
+		if existing, ok := seen[key]; ok {
+			if existing == value {
+				errs.Add(enum.Pos, "Enum %s defines the value `%s` more than once", enum.Name, value)
+			} else {
+				errs.Add(enum.Pos, "Value `%s` of enum %s conflicts with `%s`", value, enum.Name, existing)
+			}
+			continue
+		}
+
+		seen[key] = value
+	}
+}
+
+// validateGeneratedName reports type and enum names that would redeclare an
+// identifier of the generated package.
+func (s *Schema) validateGeneratedName(kind string, name string, pos Pos, errs *ErrorList) {
+	if name == "" {
+		return
+	}
+
+	exported := Capitalize(name)
+	if generatedNames[exported] {
+		errs.Add(pos, "%s name `%s` is reserved by the generated code", kind, name)
+		return
+	}
+
+	if resolved, ok := strings.CutPrefix(exported, "ResolveFor"); ok && s.Types[resolved] != nil {
+		errs.Add(pos, "%s name `%s` conflicts with the resolver function generated for type %s", kind, name, resolved)
+	}
+}
+
+// declaredAt returns the position of the type or enum named name.
+func (s *Schema) declaredAt(name string) (Pos, bool) {
+	if t, ok := s.Types[name]; ok {
+		return t.Pos, true
+	}
+
+	if enum, ok := s.Enums[name]; ok {
+		return enum.Pos, true
+	}
+
+	return Pos{}, false
+}
+
+func (s *Schema) validateType(t *Type, errs *ErrorList) {
+	if !identifierRegex.MatchString(t.Name) {
+		errs.Add(t.Pos, "Type name `%s` is not a valid identifier", t.Name)
+	}
+
+	if Builtins[t.Name] {
+		errs.Add(t.Pos, "Type name `%s` conflicts with a builtin type", t.Name)
+	} else if identifierRegex.MatchString(t.Name) && !unicode.IsUpper(rune(t.Name[0])) {
+		// Types are referenced by their name in the generated code, so they
+		// must be exported like the struct generated for them.
+		errs.Add(t.Pos, "Type name `%s` must start with an uppercase letter", t.Name)
+	}
+
+	s.validateGeneratedName("Type", t.Name, t.Pos, errs)
+
+	for _, field := range t.Fields {
+		// Inherited fields are validated with the type declaring them.
+		if field.InheritedFrom == "" {
+			s.validateField(field, "Field", errs)
+		}
+	}
+
+	validateFieldNames(t.Fields, "type "+t.Name, func(field Field) Pos {
+		if field.InheritedFrom != "" {
+			return t.ExtendsPos
+		}
+
+		return field.Pos
+	}, errs)
+
+	id, hasID := t.Fields["id"]
+	if hasID && !Builtins[id.Type] && id.InheritedFrom == "" {
+		errs.Add(id.Pos, "Type %s has an `id` field of type %s, but `id` must be a scalar", t.Name, id.Type)
+	}
+
+	if hasID {
+		return
+	}
+
+	for _, field := range t.Fields {
+		pos := field.Pos
+		if field.InheritedFrom != "" {
+			pos = t.ExtendsPos
+		}
+
+		if _, ok := s.Types[strings.TrimPrefix(field.Type, "[]")]; ok {
+			errs.Add(pos, "Type %s must define an `id` field to resolve its `%s` field", t.Name, field.Name)
+		}
+	}
+}
+
+// validateFieldNames reports fields generated as the same struct field, like
+// `name` and `Name`, against the field declared first. pos returns the
+// position to report a field at.
+func validateFieldNames(fields map[string]Field, owner string, pos func(Field) Pos, errs *ErrorList) {
+	seen := make(map[string]Field, len(fields))
+	for _, field := range SortedFields(fields) {
+		name := FieldName(field.Name)
+		if existing, ok := seen[name]; ok {
+			// Fields inherited from the same type are reported there.
+			if field.InheritedFrom != "" && field.InheritedFrom == existing.InheritedFrom {
+				continue
+			}
+
+			errs.Add(pos(field), "Field `%s` of %s conflicts with `%s`, both are generated as %s", field.Name, owner, existing.Name, name)
+			continue
+		}
+
+		seen[name] = field
+	}
+}
+
+func (s *Schema) validateField(field Field, kind string, errs *ErrorList) {
+	if !identifierRegex.MatchString(field.Name) {
+		errs.Add(field.Pos, "%s name `%s` is not a valid identifier", kind, field.Name)
+	}
+
+	if !s.isDefined(field.Type) {
+		errs.Add(field.Pos, "Type %s is not defined for `%s`", field.Type, field.Name)
+	}
+}
+
+// isDefined returns true if t is a builtin, declared type, or enum, or a list
+// of one.
+func (s *Schema) isDefined(t string) bool {
+	normalized := strings.TrimPrefix(t, "[]")
+	_, isType := s.Types[normalized]
+
+	return isType || s.IsScalar(normalized)
+}
+
+// IsScalar returns true if t is a builtin or an enum, which are represented
+// by a single value instead of an object.
+func (s *Schema) IsScalar(t string) bool {
+	_, isEnum := s.Enums[t]
+
+	return isEnum || Builtins[t]
+}
+
+func (s *Schema) validateEndpoint(e *Endpoint, errs *ErrorList) {
+	route := e.Method + " " + e.Path
+
+	if !methods[e.Method] {
+		errs.Add(e.Pos, "Invalid HTTP method `%s` for %s", e.Method, route)
+	}
+
+	if !strings.HasPrefix(e.Path, "/") {
+		errs.Add(e.Pos, "Path `%s` must start with `/`", e.Path)
+	}
+
+	if e.route() == schemaEndpointRoute {
+		errs.Add(e.Pos, "Endpoint %s conflicts with the schema endpoint served by the generated code", route)
+	}
+
+	switch {
+	case e.Name == "":
+		errs.Add(e.Pos, "`name` is not defined for %s", route)
+	case !identifierRegex.MatchString(e.Name):
+		errs.Add(e.Pos, "Endpoint name `%s` is not a valid identifier", e.Name)
+	case len(e.Args) > 0:
+		inputName := Capitalize(e.Name) + "Input"
+		if pos, ok := s.declaredAt(inputName); ok {
+			errs.Add(e.Pos, "The input of endpoint %s is generated as %s, which conflicts with the declaration at %s", e.Name, inputName, pos)
+		}
+	}
+
+	s.validateResponses(e, errs)
+
+	validateFieldNames(e.Args, "the input of "+route, func(arg Field) Pos { return arg.Pos }, errs)
+
+	pathParams := e.PathParams()
+	for _, arg := range e.Args {
+		s.validateField(arg, "Input", errs)
+
+		if arg.Source == InputBody {
+			continue
+		}
+
+		if arg.Source == InputPath && arg.IsOptional {
+			errs.Add(arg.Pos, "Path parameter `%s` can not be optional for %s", arg.Name, route)
+		}
+
+		if !s.IsScalar(arg.Type) && s.isDefined(arg.Type) {
+			errs.Add(arg.Pos, "The %s parameter `%s` of %s must be a scalar, got %s", arg.Source, arg.Name, route, arg.Type)
+		}
+	}
+
+	for _, param := range pathParams {
+		if _, ok := e.Args[param]; !ok {
+			errs.Add(e.Pos, "Path parameter `%s` is not defined in `input` for %s", param, route)
+		}
+	}
+}
+
+func (s *Schema) validateResponses(e *Endpoint, errs *ErrorList) {
+	route := e.Method + " " + e.Path
+
+	if len(e.Responses) == 0 {
+		errs.Add(e.Pos, "`response` is not defined for %s", route)
+		return
+	}
+
+	successes := 0
+	errorBodies := make(map[string]bool, len(e.Responses))
+
+	for _, response := range e.Responses {
+		if response.Status < 100 || response.Status > 599 {
+			errs.Add(response.Pos, "Invalid status %d for %s", response.Status, route)
+			continue
+		}
+
+		if response.IsSuccess() {
+			successes++
+		}
+
+		if response.Body == "" {
+			continue
+		}
+
+		if response.Status == 204 {
+			errs.Add(response.Pos, "204 responses can not have a body for %s", route)
+		}
+
+		if _, ok := s.Types[strings.TrimPrefix(response.Body, "[]")]; !ok {
+			errs.Add(response.Pos, "Type %s is not defined for the %d response of %s", response.Body, response.Status, route)
+			continue
+		}
+
+		if response.IsSuccess() {
+			continue
+		}
+
+		if _, ok := s.Types[response.Body]; !ok {
+			errs.Add(response.Pos, "The %d response of %s must be a single type, got %s", response.Status, route, response.Body)
+			continue
+		}
+
+		if errorBodies[response.Body] {
+			errs.Add(response.Pos, "Type %s is used by more than one error response of %s", response.Body, route)
+		}
+
+		// Error response types implement error, so their Error method would
+		// collide with a field generated with the same name.
+		for _, field := range SortedFields(s.Types[response.Body].Fields) {
+			if FieldName(field.Name) == "Error" {
+				errs.Add(response.Pos, "Type %s can not be used by the %d response of %s because its `%s` field conflicts with the generated Error method", response.Body, response.Status, route, field.Name)
+			}
+		}
+		errorBodies[response.Body] = true
+	}
+
+	if successes != 1 {
+		errs.Add(e.Pos, "Exactly one successful (2xx) response must be defined for %s", route)
+	}
+}
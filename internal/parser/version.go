@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is the newest version of the schema format supported by this
+// version of overtime. Schemas declaring an older version with the same major
+// version are supported too, since the format only changes in backwards
+// compatible ways within a major version.
+const Version = "0.1.0"
+
+// CheckVersion returns an ErrorList when the schema is written against a
+// version of the schema format this version of overtime doesn't support.
+// Schemas without a version are assumed to be compatible.
+func (s *Schema) CheckVersion() error {
+	if s.Version == "" {
+		return nil
+	}
+
+	errs := ErrorList{}
+
+	version, ok := parseVersion(s.Version)
+	if !ok {
+		errs.Add(s.VersionPos, "Invalid schema version `%s`, expected a version like %s", s.Version, Version)
+		return errs.Err()
+	}
+
+	supported, _ := parseVersion(Version)
+
+	switch {
+	case version[0] < supported[0]:
+		errs.Add(s.VersionPos, "Schema version %s is no longer supported by this version of overtime, which supports %d.x.x schemas. Upgrade the schema to version %s", s.Version, supported[0], Version)
+	case compareVersions(version, supported) > 0:
+		errs.Add(s.VersionPos, "Schema version %s is newer than this version of overtime supports (%s). Upgrade overtime to use it", s.Version, Version)
+	}
+
+	return errs.Err()
+}
+
+// parseVersion parses a `major.minor.patch` version, where the minor and
+// patch versions default to 0 when omitted.
+func parseVersion(s string) ([3]int, bool) {
+	version := [3]int{}
+
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > len(version) {
+		return version, false
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, false
+		}
+
+		version[i] = n
+	}
+
+	return version, true
+}
+
+// compareVersions returns -1, 0, or 1 when a is older than, the same as, or
+// newer than b.
+func compareVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,219 @@
+// Scaffold creates the files needed to start a new overtime project from one
+// of the bundled templates.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/blakewilliams/overtime/generator"
+	"github.com/blakewilliams/overtime/internal/config"
+	"github.com/blakewilliams/overtime/internal/parser"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// DefaultTemplate is the template used when none is given.
+const DefaultTemplate = "minimal"
+
+// smokePaths are the paths requested by the generated smoke test of each
+// template. Every template must respond with a 200 on its smoke path.
+var smokePaths = map[string]string{
+	"minimal": "/status",
+	"gateway": "/api/posts",
+}
+
+type (
+	// Options configure the project created by Init.
+	Options struct {
+		// Template is the name of the template to create the project from.
+		Template string
+		// Module is the import path of the project. When empty, the module of
+		// an existing go.mod is used, falling back to the directory name.
+		Module string
+		// Package is the name of the generated package.
+		Package string
+	}
+
+	// templateData is passed to every rendered template file.
+	templateData struct {
+		Module    string
+		Package   string
+		SmokePath string
+	}
+
+	// file is a single file created by Init.
+	file struct {
+		path    string
+		content []byte
+	}
+)
+
+// Templates returns the names of the available templates.
+func Templates() []string {
+	names := make([]string, 0, len(smokePaths))
+	for name := range smokePaths {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Init creates a new project in dir, returning the paths of the files it
+// created. No files are written if any of them already exist.
+func Init(dir string, opts Options) ([]string, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+
+	if opts.Package == "" {
+		opts.Package = generator.DefaultPackageName
+	}
+
+	smokePath, ok := smokePaths[opts.Template]
+	if !ok {
+		return nil, fmt.Errorf("Unknown template %s, expected one of %s", opts.Template, strings.Join(Templates(), ", "))
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]file, 0, 7)
+
+	module := moduleName(dir, opts.Module)
+
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); os.IsNotExist(err) {
+		files = append(files, file{"go.mod", []byte(fmt.Sprintf("module %s\n\ngo 1.22\n", module))})
+	}
+
+	data := templateData{Module: module, Package: opts.Package, SmokePath: smokePath}
+
+	schema, err := templates.ReadFile(templatePath(opts.Template, "schema.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file{"schema.yaml", schema})
+
+	cfg := &config.Config{
+		Schemas: []string{"schema.yaml"},
+		Module:  module,
+		Output:  config.Output{Package: opts.Package},
+	}
+	rawConfig, err := cfg.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file{config.Filename, rawConfig})
+
+	for _, name := range []string{"main.go", "main_test.go"} {
+		content, err := render(templatePath("common", name+".tmpl"), data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file{name, content})
+	}
+
+	impl, err := render(templatePath(opts.Template, "impl.go.tmpl"), data)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file{filepath.Join(opts.Package, "impl.go"), impl})
+
+	generated, err := generate(schema, opts.Package)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file{filepath.Join(opts.Package, "generated.go"), generated})
+
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f.path)); err == nil {
+			return nil, fmt.Errorf("%s already exists", filepath.Join(dir, f.path))
+		}
+	}
+
+	created := make([]string, len(files))
+	for i, f := range files {
+		created[i] = filepath.Join(dir, f.path)
+
+		if err := os.MkdirAll(filepath.Dir(created[i]), 0755); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(created[i], f.content, 0644); err != nil {
+			return nil, fmt.Errorf("Failed to write to file %s: %w", created[i], err)
+		}
+	}
+
+	return created, nil
+}
+
+// templatePath returns the path of a file in the embedded templates.
+// Embedded paths always use forward slashes.
+func templatePath(dir string, name string) string {
+	return "templates/" + dir + "/" + name
+}
+
+// render executes the embedded template at name, formatting the result.
+func render(name string, data templateData) ([]byte, error) {
+	raw, err := templates.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// generate returns the generated package for the starter schema.
+func generate(schema []byte, packageName string) ([]byte, error) {
+	parsed, err := parser.Parse(bytes.NewReader(schema))
+	if err != nil {
+		return nil, err
+	}
+
+	gen := generator.NewGo(parsed)
+	gen.PackageName = packageName
+
+	r, err := gen.Coordinator()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// moduleName returns the module path of the project. An explicit module
+// wins, then the module of an existing go.mod, then the directory name.
+func moduleName(dir string, module string) string {
+	if module != "" {
+		return module
+	}
+
+	if module := config.ReadModule(filepath.Join(dir, "go.mod")); module != "" {
+		return module
+	}
+
+	return filepath.Base(dir)
+}
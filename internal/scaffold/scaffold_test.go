@@ -0,0 +1,61 @@
+package scaffold
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitCreatesWorkingProjects(t *testing.T) {
+	for _, name := range Templates() {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			created, err := Init(dir, Options{Template: name, Module: "example.com/gateway"})
+			require.NoError(t, err)
+			require.Len(t, created, 7)
+
+			goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			require.NoError(t, err)
+			require.Contains(t, string(goMod), "module example.com/gateway")
+
+			cmd := exec.Command("go", "test", "./...")
+			cmd.Dir = dir
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stdout
+			require.NoError(t, cmd.Run())
+		})
+	}
+}
+
+func TestInitUsesExistingModule(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/existing\n\ngo 1.22\n"), 0644))
+
+	created, err := Init(dir, Options{})
+	require.NoError(t, err)
+	require.NotContains(t, created, filepath.Join(dir, "go.mod"))
+
+	main, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(main), `"example.com/existing/overtime"`)
+}
+
+func TestInitRefusesToOverwriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+
+	_, err := Init(dir, Options{})
+	require.ErrorContains(t, err, "main.go already exists")
+
+	_, err = os.Stat(filepath.Join(dir, "schema.yaml"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestInitRejectsUnknownTemplates(t *testing.T) {
+	_, err := Init(t.TempDir(), Options{Template: "nope"})
+	require.ErrorContains(t, err, "Unknown template nope, expected one of gateway, minimal")
+}
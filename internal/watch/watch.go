@@ -0,0 +1,102 @@
+// Watch polls files for changes, calling a function once they settle. Polling
+// is used instead of filesystem events so it behaves the same on every
+// platform and when editors replace files instead of writing to them.
+package watch
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+type (
+	// Watcher calls OnChange whenever one of the watched files is created,
+	// modified, or removed.
+	Watcher struct {
+		// Paths returns the files to watch. It is only called when watching
+		// starts and again after a change, so the watched files can change
+		// too, e.g. when a config file lists a new schema. Directories can be
+		// watched to notice files being added to or removed from them.
+		Paths func() []string
+		// OnChange is called once the files stop changing for Debounce.
+		OnChange func()
+		// Interval is how often the files are checked for changes.
+		Interval time.Duration
+		// Debounce is how long the files must be unchanged before OnChange is
+		// called, so editors writing several times only trigger one call.
+		Debounce time.Duration
+	}
+
+	// fileState is the state of a file used to detect changes.
+	fileState struct {
+		exists  bool
+		size    int64
+		modTime time.Time
+	}
+)
+
+// Run watches the files until ctx is canceled.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	paths := w.Paths()
+	last := snapshot(paths)
+	var changedAt time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if !equal(last, snapshot(paths)) {
+				// The change may affect which files are watched, so they
+				// are listed again.
+				paths = w.Paths()
+				last = snapshot(paths)
+				changedAt = now
+				continue
+			}
+
+			if changedAt.IsZero() || now.Sub(changedAt) < w.Debounce {
+				continue
+			}
+
+			// last was taken before calling OnChange, so files changing while
+			// it runs, like a schema saved during generation, are reported by
+			// the next tick.
+			changedAt = time.Time{}
+			w.OnChange()
+		}
+	}
+}
+
+func snapshot(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			states[path] = fileState{}
+			continue
+		}
+
+		states[path] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return states
+}
+
+func equal(a map[string]fileState, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || !state.modTime.Equal(other.modTime) || state.size != other.size || state.exists != other.exists {
+			return false
+		}
+	}
+
+	return true
+}
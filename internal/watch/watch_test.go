@@ -0,0 +1,145 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherDebouncesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("types: {}\n"), 0o644))
+
+	var calls atomic.Int32
+	changed := make(chan struct{}, 10)
+
+	w := &Watcher{
+		Paths:    func() []string { return []string{path} },
+		OnChange: func() { calls.Add(1); changed <- struct{}{} },
+		Interval: 5 * time.Millisecond,
+		Debounce: 50 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	// Give the watcher time to take its first snapshot.
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(path, []byte("types: {}\n"+string(rune('a'+i))+"\n"), 0o644))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnChange was not called")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(1), calls.Load(), "Writes in quick succession should trigger a single change")
+
+	require.NoError(t, os.Remove(path))
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnChange was not called after removing the file")
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherOnlyListsPathsAfterChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("types: {}\n"), 0o644))
+
+	var listed atomic.Int32
+	changed := make(chan struct{}, 10)
+
+	w := &Watcher{
+		Paths:    func() []string { listed.Add(1); return []string{path} },
+		OnChange: func() { changed <- struct{}{} },
+		Interval: 5 * time.Millisecond,
+		Debounce: 20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), listed.Load(), "Paths should only be called when watching starts")
+
+	require.NoError(t, os.WriteFile(path, []byte("types: {}\nchanged: true\n"), 0o644))
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnChange was not called")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	afterChange := listed.Load()
+	require.Greater(t, afterChange, int32(1), "Paths should be called after the change")
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, afterChange, listed.Load(), "Paths should not be called while nothing changes")
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherReportsChangesMadeWhileHandlingAChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("types: {}\n"), 0o644))
+
+	var calls atomic.Int32
+	changed := make(chan struct{}, 10)
+
+	w := &Watcher{
+		Paths: func() []string { return []string{path} },
+		OnChange: func() {
+			// Simulate the schema being saved while generating.
+			if calls.Add(1) == 1 {
+				require.NoError(t, os.WriteFile(path, []byte("types: {}\nsaved: during generation\n"), 0o644))
+			}
+
+			changed <- struct{}{}
+		},
+		Interval: 5 * time.Millisecond,
+		Debounce: 20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("types: {}\nchanged: true\n"), 0o644))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("OnChange was not called for the change made while it was running")
+		}
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
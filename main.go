@@ -1,128 +1,758 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/blakewilliams/overtime/generator"
+	"github.com/blakewilliams/overtime/internal/config"
+	"github.com/blakewilliams/overtime/internal/diff"
 	"github.com/blakewilliams/overtime/internal/parser"
+	"github.com/blakewilliams/overtime/internal/scaffold"
+	"github.com/blakewilliams/overtime/internal/watch"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
-	app := &cli.App{
+	if err := newApp().Run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		defer func() {
+			os.Exit(1)
+		}()
+	}
+}
+
+func newApp() *cli.App {
+	return &cli.App{
 		Name:  "overtime",
 		Usage: "A tool for generating a (federated) REST gateway from a schema",
 
 		Commands: []*cli.Command{
 			{
-				Name:  "init",
-				Usage: "Generates a basic config and schema for hosting a gateway",
+				Name:      "init",
+				Usage:     "Generates a basic config and schema for hosting a gateway",
+				ArgsUsage: "[directory]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "template",
+						Usage: "The template to create the project from, one of " + strings.Join(scaffold.Templates(), ", "),
+						Value: scaffold.DefaultTemplate,
+					},
+					&cli.StringFlag{
+						Name:  "module",
+						Usage: "The module path of the project, defaults to the module in go.mod or the directory name",
+					},
+					&cli.StringFlag{
+						Name:  "package",
+						Usage: "The name of the generated package",
+						Value: generator.DefaultPackageName,
+					},
+				},
 				Action: func(c *cli.Context) error {
+					dir := "."
+					if c.Args().Len() > 0 {
+						dir = c.Args().First()
+					}
+
 					fmt.Println("Initializing a new overtime project")
+
+					created, err := scaffold.Init(dir, scaffold.Options{
+						Template: c.String("template"),
+						Module:   c.String("module"),
+						Package:  c.String("package"),
+					})
+					if err != nil {
+						return err
+					}
+
+					for _, path := range created {
+						fmt.Printf("Created %s\n", path)
+					}
+
+					fmt.Println("Done! Run `go run .` to start the gateway")
+
 					return nil
 				},
 			},
+			{
+				Name:      "validate",
+				Usage:     "Validate a schema without generating any code",
+				ArgsUsage: "[schema...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "config",
+						Usage: "The config file listing the schema to validate when none is passed",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "The output format, either text or json",
+						Value: "text",
+					},
+				},
+				Action: validate,
+			},
+			{
+				Name:      "diff",
+				Usage:     "List the changes between two versions of a schema, exiting with a non-zero status when any of them are breaking",
+				ArgsUsage: "<old schema> <new schema>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "The output format, either text or json",
+						Value: "text",
+					},
+				},
+				Action: compare,
+			},
 			{
 				Name:    "generate",
 				Aliases: []string{"g"},
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "package name",
-						Usage: "The name of the package to generate",
+						Name:  "config",
+						Usage: "The config file to use, defaults to the " + config.Filename + " found in the working directory or its parents",
+					},
+					&cli.StringFlag{
+						Name:    "package",
+						Aliases: []string{"p"},
+						Usage:   "The name of the package to generate",
 					},
 					&cli.StringFlag{
-						Name:  "directory",
-						Usage: "The directory to create the package in.",
+						Name:    "directory",
+						Aliases: []string{"d"},
+						Usage:   "The directory to create the package in.",
+					},
+					&cli.BoolFlag{
+						Name:  "watch",
+						Usage: "Regenerate whenever the schema or config changes",
+					},
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Exit with a non-zero status and print a diff if the generated code is out of date, without writing any files",
 					},
 				},
-				Usage: "Generate a REST gateway from a schema",
+				ArgsUsage: "[schema]",
+				Usage:     "Generate a REST gateway from a schema",
 				Action: func(c *cli.Context) error {
-					if c.Args().Len() < 1 {
-						return fmt.Errorf("You must pass a schema file to generate")
-					}
+					if c.Bool("watch") {
+						if c.Bool("check") {
+							return fmt.Errorf("--watch can not be combined with --check")
+						}
 
-					log.Println("Generating a REST gateway from the provided schema...")
-					schemaFilePath := c.Args().First()
-					if _, err := os.Stat(schemaFilePath); os.IsNotExist(err) {
-						return fmt.Errorf("The schema file %s does not exist", schemaFilePath)
+						return watchAndGenerate(c)
 					}
 
-					// TODO use io.Reader instead of os.ReadFile
-					schemaFile, err := os.Open(schemaFilePath)
+					cfg, err := loadConfig(c)
 					if err != nil {
-						return fmt.Errorf("Failed to read the schema file %s: %w", schemaFilePath, err)
+						return err
 					}
 
-					schema, err := parser.Parse(schemaFile)
-					if err != nil {
-						return fmt.Errorf("Failed to parse the schema: %w", err)
-					}
-					gen := generator.NewGo(schema)
-					gen.PackageName = "overtime"
-					if packageName := c.String("package name"); packageName != "" {
-						gen.PackageName = packageName
-					}
+					return generate(c.App.Writer, cfg, c.Bool("check"))
+				},
+			},
+		},
+	}
+}
 
-					cwd, err := os.Getwd()
-					if err != nil {
-						return fmt.Errorf("Failed to get the current working directory: %w", err)
-					}
+// validate checks the schemas passed as arguments, or listed by the config,
+// printing every problem found. With --format json, problems loading the
+// config are reported in the same shape as problems with the schema.
+func validate(c *cli.Context) error {
+	format := c.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("Unknown format %s, expected text or json", format)
+	}
 
-					rootPath := path.Join(cwd, gen.PackageName)
-					if directory := c.String("directory"); directory != "" {
-						rootPath = path.Join(cwd, directory, gen.PackageName)
-					}
+	schemaPaths := c.Args().Slice()
+	if len(schemaPaths) == 0 {
+		cfg, err := loadConfig(c)
+		if err != nil {
+			if format == "text" {
+				return err
+			}
 
-					if err := writeFile(path.Join(rootPath, "generated.go"), gen.Coordinator()); err != nil {
-						return err
-					}
+			if err := printProblemsJSON(c.App.Writer, schemaProblems("", err)); err != nil {
+				return err
+			}
 
-					if _, err := os.Stat(path.Join(rootPath, "impl.go")); os.IsNotExist(err) {
-						if err := writeFile(path.Join(rootPath, "impl.go"), gen.Root()); err != nil {
-							return err
-						}
-					}
+			return cli.Exit("", 1)
+		}
 
-					fmt.Println("Done!")
+		schemaPaths = cfg.SchemaPaths()
+	}
 
-					return nil
-				},
-			},
-		},
+	schemaFilePath := strings.Join(schemaPaths, ", ")
+	_, err := parseSchema(schemaPaths...)
+	problems := schemaProblems(schemaFilePath, err)
+
+	if format == "json" {
+		if err := printProblemsJSON(c.App.Writer, problems); err != nil {
+			return err
+		}
+	} else {
+		printProblems(c.App.Writer, schemaFilePath, problems)
 	}
 
-	if err := app.Run(os.Args); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		defer func() {
-			os.Exit(1)
-		}()
+	if len(problems) > 0 {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
+
+// The exit statuses of `overtime diff`, so CI can tell breaking changes apart
+// from schemas that couldn't be compared.
+const (
+	diffBreakingStatus = 1
+	diffErrorStatus    = 2
+)
+
+// compare lists the changes between the two schemas passed as arguments,
+// exiting with diffBreakingStatus when any of them are breaking, and with
+// diffErrorStatus when the schemas can't be compared. With --format json,
+// schemas that fail to parse are reported as problems.
+func compare(c *cli.Context) error {
+	format := c.String("format")
+	if format != "text" && format != "json" {
+		return cli.Exit(fmt.Sprintf("error: Unknown format %s, expected text or json", format), diffErrorStatus)
+	}
+
+	changes, err := compareSchemas(c.Args().Slice())
+	if err != nil {
+		if format == "text" {
+			return cli.Exit(fmt.Sprintf("error: %v", err), diffErrorStatus)
+		}
+
+		if err := printChangesJSON(c.App.Writer, nil, schemaProblems(strings.Join(c.Args().Slice(), ", "), err)); err != nil {
+			return err
+		}
+
+		return cli.Exit("", diffErrorStatus)
+	}
+
+	if format == "json" {
+		if err := printChangesJSON(c.App.Writer, changes, []problem{}); err != nil {
+			return err
+		}
+	} else {
+		printChanges(c.App.Writer, changes)
+	}
+
+	if diff.HasBreaking(changes) {
+		return cli.Exit("", diffBreakingStatus)
+	}
+
+	return nil
+}
+
+// compareSchemas parses the old and new schema in paths and compares them.
+func compareSchemas(paths []string) ([]diff.Change, error) {
+	if len(paths) != 2 {
+		return nil, fmt.Errorf("Expected an old and a new schema to compare")
+	}
+
+	oldSchema, err := parseSchema(paths[0])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse the old schema: %w", err)
+	}
+
+	newSchema, err := parseSchema(paths[1])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse the new schema: %w", err)
+	}
+
+	return diff.Compare(oldSchema, newSchema), nil
+}
+
+// generate writes the generated code for the config, or compares it with the
+// code on disk when check is true, reporting what it did to w.
+func generate(w io.Writer, cfg *config.Config, check bool) error {
+	log.Println("Generating a REST gateway from the provided schema...")
+
+	schema, err := parseSchema(cfg.SchemaPaths()...)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the schema: %w", err)
+	}
+	server := cfg.Generators["server"]
+	gen := generator.NewGo(schema)
+	gen.PackageName = server.Package
+	gen.SchemaEndpoint = server.Enabled("schema_endpoint")
+	rootPath := cfg.PackageDir("server")
+
+	generated, err := gen.Coordinator()
+	if err != nil {
+		return err
+	}
+
+	if check {
+		stale, err := checkFile(w, path.Join(rootPath, "generated.go"), generated)
+		if err != nil {
+			return err
+		}
+
+		if stale {
+			return cli.Exit("", 1)
+		}
+
+		fmt.Fprintln(w, "Generated code is up to date")
+
+		return nil
+	}
+
+	if err := writeIfChanged(w, path.Join(rootPath, "generated.go"), generated); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path.Join(rootPath, "impl.go")); os.IsNotExist(err) {
+		impl, err := gen.Root()
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(w, path.Join(rootPath, "impl.go"), impl); err != nil {
+			return err
+		}
+	} else {
+		impl, err := gen.UpdateRoot(rootPath)
+		if err != nil {
+			return fmt.Errorf("Failed to add stubs to impl.go: %w", err)
+		}
+
+		if impl != nil {
+			if err := writeFile(w, path.Join(rootPath, "impl.go"), impl); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "Done!")
+
+	return nil
+}
+
+// parseSchema parses the schema split across paths, returning an error when
+// it's written against a version of the schema format this version of
+// overtime doesn't support.
+func parseSchema(paths ...string) (*parser.Schema, error) {
+	schema, err := parser.ParseFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schema.CheckVersion(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// watchAndGenerate generates the code, then regenerates it whenever the
+// config or schema changes until interrupted. Errors are printed instead of
+// stopping the watcher so the schema can be fixed and saved again.
+func watchAndGenerate(c *cli.Context) error {
+	run := func() {
+		cfg, err := loadConfig(c)
+		if err == nil {
+			err = generate(c.App.Writer, cfg, false)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 	}
+
+	run()
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
+	defer stop()
+
+	watcher := &watch.Watcher{
+		Paths:    func() []string { return watchedPaths(c) },
+		OnChange: run,
+		Interval: 250 * time.Millisecond,
+		Debounce: 100 * time.Millisecond,
+	}
+
+	fmt.Println("Watching for changes, press Ctrl+C to stop")
+	if err := watcher.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
 }
 
-func writeFile(path string, r io.Reader) error {
-	f, err := os.Create(path)
+// watchedPaths returns the config and schema files the generated code
+// depends on.
+func watchedPaths(c *cli.Context) []string {
+	paths := make([]string, 0)
+
+	if configPath, err := findConfig(c); err == nil && configPath != "" {
+		paths = append(paths, configPath)
+	}
+
+	if cfg, err := loadConfig(c); err == nil {
+		// The directories of glob patterns are watched too, so files added
+		// to them are noticed and the patterns expanded again.
+		for _, pattern := range cfg.SchemaPaths() {
+			matches, err := filepath.Glob(pattern)
+			if err != nil || len(matches) == 0 {
+				matches = []string{pattern}
+			}
+
+			if strings.ContainsAny(pattern, "*?[") {
+				paths = append(paths, filepath.Dir(pattern))
+			}
+
+			paths = append(paths, matches...)
+		}
+
+		// Imported files are only known once the schema parses, until then
+		// the configured schemas are enough to notice it being fixed.
+		if schema, err := parser.ParseFiles(cfg.SchemaPaths()...); err == nil {
+			paths = append(paths, schema.Files...)
+		}
+	}
+
+	return paths
+}
+
+// findConfig returns the path of the config file from the --config flag,
+// falling back to the one found from the working directory. An empty path is
+// returned when there is no config file.
+func findConfig(c *cli.Context) (string, error) {
+	if configPath := c.String("config"); configPath != "" {
+		return configPath, nil
+	}
+
+	configPath, err := config.Find(".")
+	if errors.Is(err, config.ErrNotFound) {
+		return "", nil
+	}
+
+	return configPath, err
+}
+
+// loadConfig returns the project config for a command. The config is read
+// from the --config flag or discovered from the working directory, then a
+// schema passed as an argument and the output flags override it.
+func loadConfig(c *cli.Context) (*config.Config, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the current working directory: %w", err)
+	}
+
+	configPath, err := findConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *config.Config
+	if configPath != "" {
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Args().Len() > 0 {
+		schemaFilePath, err := filepath.Abs(c.Args().First())
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg == nil {
+			cfg = config.Default(cwd, schemaFilePath)
+		} else {
+			cfg.Schemas = []string{schemaFilePath}
+		}
+	}
+
+	if cfg == nil {
+		return nil, fmt.Errorf("You must pass a schema file or create an %s", config.Filename)
+	}
+
+	server, ok := cfg.Generators["server"]
+	if !ok {
+		return nil, fmt.Errorf("The server generator must be enabled in %s", config.Filename)
+	}
+
+	if packageName := c.String("package"); packageName != "" {
+		server.Package = packageName
+	}
+
+	// Like the schema argument, --directory is relative to the working
+	// directory rather than to the config.
+	if directory := c.String("directory"); directory != "" {
+		server.Directory, err = filepath.Abs(directory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// problem is a single schema problem reported by the validate command.
+type problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// schemaProblems converts the error returned when parsing a schema into a
+// list of problems. Errors without a position, like a missing file, are
+// reported against the schema file itself.
+func schemaProblems(schemaFilePath string, err error) []problem {
+	if err == nil {
+		return []problem{}
+	}
+
+	var errs parser.ErrorList
+	if !errors.As(err, &errs) {
+		return []problem{{File: schemaFilePath, Message: err.Error()}}
+	}
+
+	problems := make([]problem, len(errs))
+	for i, err := range errs {
+		problems[i] = problem{
+			File:    err.Pos.Filename,
+			Line:    err.Pos.Line,
+			Column:  err.Pos.Column,
+			Message: err.Message,
+		}
+	}
+
+	return problems
+}
+
+func printProblems(w io.Writer, schemaFilePath string, problems []problem) {
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "%s is valid\n", schemaFilePath)
+		return
+	}
+
+	for _, p := range problems {
+		if p.Line == 0 {
+			fmt.Fprintf(w, "%s: %s\n", p.File, p.Message)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s:%d:%d: %s\n", p.File, p.Line, p.Column, p.Message)
+	}
+
+	fmt.Fprintf(w, "\nFound %d problem(s) in %s\n", len(problems), schemaFilePath)
+}
+
+func printProblemsJSON(w io.Writer, problems []problem) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]any{
+		"valid":    len(problems) == 0,
+		"problems": problems,
+	})
+}
+
+// location is the position of a changed element reported by the diff
+// command.
+type location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// change is a single change reported by the diff command.
+type change struct {
+	Kind     diff.Kind `json:"kind"`
+	Breaking bool      `json:"breaking"`
+	Message  string    `json:"message"`
+	Old      *location `json:"old,omitempty"`
+	New      *location `json:"new,omitempty"`
+}
+
+func newLocation(pos parser.Pos) *location {
+	if pos.Line == 0 {
+		return nil
+	}
+
+	return &location{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+// printChanges lists the breaking changes first, each at its position in the
+// new schema, or in the old schema for removed elements.
+func printChanges(w io.Writer, changes []diff.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+
+	breaking := make([]diff.Change, 0, len(changes))
+	other := make([]diff.Change, 0, len(changes))
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		} else {
+			other = append(other, c)
+		}
+	}
+
+	printChangeList(w, "Breaking changes", breaking)
+	printChangeList(w, "Other changes", other)
+
+	fmt.Fprintf(w, "Found %d breaking change(s) and %d other change(s)\n", len(breaking), len(other))
+}
+
+func printChangeList(w io.Writer, heading string, changes []diff.Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s:\n", heading)
+	for _, c := range changes {
+		pos := c.New
+		if pos.Line == 0 {
+			pos = c.Old
+		}
+
+		fmt.Fprintf(w, "  %s: %s\n", pos, c.Message)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// printChangesJSON prints the changes along with the problems that kept the
+// schemas from being compared, if any.
+func printChangesJSON(w io.Writer, changes []diff.Change, problems []problem) error {
+	encoded := make([]change, len(changes))
+	for i, c := range changes {
+		encoded[i] = change{
+			Kind:     c.Kind,
+			Breaking: c.Breaking,
+			Message:  c.Message,
+			Old:      newLocation(c.Old),
+			New:      newLocation(c.New),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]any{
+		"breaking": diff.HasBreaking(changes),
+		"changes":  encoded,
+		"problems": problems,
+	})
+}
+
+// checkFile compares the expected contents of a file with the file on disk,
+// writing a unified diff to w and returning true when they differ. A missing
+// file is compared as if it were empty.
+func checkFile(w io.Writer, path string, expected io.Reader) (bool, error) {
+	want, err := io.ReadAll(expected)
+	if err != nil {
+		return false, err
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if bytes.Equal(got, want) {
+		return false, nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(got)),
+		B:        difflib.SplitLines(string(want)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprint(w, diff)
+	fmt.Fprintf(w, "\n%s is out of date, run `overtime generate` to update it\n", path)
+
+	return true, nil
+}
+
+// writeIfChanged writes r to path unless the file already has the same
+// contents, so tools watching the output don't rebuild for nothing.
+func writeIfChanged(w io.Writer, path string, r io.Reader) error {
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	_, err = io.Copy(f, r)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		fmt.Fprintf(w, "Unchanged %s\n", path)
+		return nil
+	}
+
+	return writeFile(w, path, bytes.NewReader(content))
+}
+
+// writeFile atomically writes r to path, creating missing directories. The
+// contents are written and synced to a temporary file that is renamed over
+// path, so a failed write or a crash never leaves a partially written file
+// behind. The created file is reported to w.
+func writeFile(w io.Writer, path string, r io.Reader) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Failed to create directory %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("Failed to write to file %s: %w", path, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("Failed to write to file %s: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
 		return fmt.Errorf("Failed to write to file %s: %w", path, err)
 	}
 
-	// run go fmt on the file
-	cmd := exec.Command("gofmt", "-w", path)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("Failed to run gofmt on file %s: %w", path, err)
+	// Rewritten files keep their mode, new files are created as 0644.
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("Failed to write to file %s: %w", path, err)
 	}
 
-	fmt.Printf("Created %s\n", path)
+	fmt.Fprintf(w, "Created %s\n", path)
 
 	return nil
 }
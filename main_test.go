@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestValidate(t *testing.T) {
+	out, code := runCLI(t, "validate", "testdata/valid.yaml")
+	require.Equal(t, 0, code)
+	require.Equal(t, "testdata/valid.yaml is valid\n", out)
+
+	out, code = runCLI(t, "validate", "testdata/invalid.yaml")
+	require.Equal(t, 1, code)
+	require.Equal(t, "testdata/invalid.yaml:5:7: Type User is not defined for `author`\n"+
+		"testdata/invalid.yaml:8:3: `response` is not defined for GET /posts\n"+
+		"\nFound 2 problem(s) in testdata/invalid.yaml\n", out)
+
+	out, code = runCLI(t, "validate", "--format", "json", "testdata/invalid.yaml")
+	require.Equal(t, 1, code)
+	require.JSONEq(t, `{
+		"valid": false,
+		"problems": [
+			{"file": "testdata/invalid.yaml", "line": 5, "column": 7, "message": "Type User is not defined for `+"`author`"+`"},
+			{"file": "testdata/invalid.yaml", "line": 8, "column": 3, "message": "`+"`response`"+` is not defined for GET /posts"}
+		]
+	}`, out)
+
+	out, code = runCLI(t, "validate", "--format", "json", "testdata/missing.yaml")
+	require.Equal(t, 1, code)
+	require.JSONEq(t, `{
+		"valid": false,
+		"problems": [{"file": "testdata/missing.yaml", "message": "open testdata/missing.yaml: no such file or directory"}]
+	}`, out)
+}
+
+func TestValidateReportsMissingConfigAsJSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/gateway\n"), 0o644))
+	chdir(t, dir)
+
+	out, code := runCLI(t, "validate", "--format", "json")
+	require.Equal(t, 1, code)
+	require.JSONEq(t, `{
+		"valid": false,
+		"problems": [{"file": "", "message": "You must pass a schema file or create an overtime.yaml"}]
+	}`, out)
+
+	out, code = runCLI(t, "validate")
+	require.Equal(t, 1, code)
+	require.Equal(t, "error: You must pass a schema file or create an overtime.yaml\n", out)
+}
+
+func TestGenerateCheck(t *testing.T) {
+	schema, err := filepath.Abs("testdata/valid.yaml")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/gateway\n"), 0o644))
+	chdir(t, dir)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	generated := filepath.Join(cwd, "overtime", "generated.go")
+
+	out, code := runCLI(t, "generate", "--check", schema)
+	require.Equal(t, 1, code)
+	require.Contains(t, out, "+// Code generated by github.com/blakewilliams/overtime DO NOT EDIT\n")
+	require.True(t, strings.HasSuffix(out, "\n"+generated+" is out of date, run `overtime generate` to update it\n"), out)
+	require.NoFileExists(t, generated, "--check should not write any files")
+
+	out, code = runCLI(t, "generate", schema)
+	require.Equal(t, 0, code, out)
+	require.FileExists(t, generated)
+
+	out, code = runCLI(t, "generate", "--check", schema)
+	require.Equal(t, 0, code)
+	require.Equal(t, "Generated code is up to date\n", out)
+
+	current, err := os.ReadFile(generated)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(generated, append(current, "// edited by hand\n"...), 0o644))
+
+	out, code = runCLI(t, "generate", "--check", schema)
+	require.Equal(t, 1, code)
+	require.Contains(t, out, "\n-// edited by hand\n")
+	require.True(t, strings.HasSuffix(out, "\n"+generated+" is out of date, run `overtime generate` to update it\n"), out)
+}
+
+func TestGenerateResolvesDirectoryFromTheWorkingDirectory(t *testing.T) {
+	schema, err := os.ReadFile("testdata/valid.yaml")
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/gateway\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "overtime.yaml"), []byte("schemas: [schema.yaml]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "schema.yaml"), schema, 0o644))
+
+	sub := filepath.Join(root, "cmd", "gateway")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	chdir(t, sub)
+
+	out, code := runCLI(t, "generate", "-d", "internal")
+	require.Equal(t, 0, code, out)
+	require.FileExists(t, filepath.Join(sub, "internal", "overtime", "generated.go"))
+	require.NoDirExists(t, filepath.Join(root, "internal"))
+}
+
+func TestWriteFileKeepsTheModeOfExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	created := filepath.Join(dir, "created.go")
+	existing := filepath.Join(dir, "existing.go")
+	require.NoError(t, os.WriteFile(existing, []byte("package old\n"), 0o600))
+	require.NoError(t, os.Chmod(existing, 0o600))
+
+	out := new(bytes.Buffer)
+	require.NoError(t, writeFile(out, created, strings.NewReader("package created\n")))
+	require.NoError(t, writeFile(out, existing, strings.NewReader("package existing\n")))
+	require.Equal(t, "Created "+created+"\nCreated "+existing+"\n", out.String())
+
+	info, err := os.Stat(created)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	info, err = os.Stat(existing)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	require.Equal(t, "package existing\n", string(content))
+}
+
+func TestDiff(t *testing.T) {
+	out, code := runCLI(t, "diff", "testdata/valid.yaml", "testdata/valid.yaml")
+	require.Equal(t, 0, code)
+	require.Equal(t, "No changes\n", out)
+
+	out, code = runCLI(t, "diff", "testdata/valid.yaml", "testdata/changed.yaml")
+	require.Equal(t, 1, code)
+	require.Equal(t, "Breaking changes:\n"+
+		"  testdata/changed.yaml:5:7: Field `title` of type Post was made optional\n"+
+		"\nFound 1 breaking change(s) and 0 other change(s)\n", out)
+
+	out, code = runCLI(t, "diff", "--format", "json", "testdata/valid.yaml", "testdata/changed.yaml")
+	require.Equal(t, 1, code)
+	require.JSONEq(t, `{
+		"breaking": true,
+		"changes": [{
+			"kind": "field-made-optional",
+			"breaking": true,
+			"message": "Field `+"`title`"+` of type Post was made optional",
+			"old": {"file": "testdata/valid.yaml", "line": 5, "column": 7},
+			"new": {"file": "testdata/changed.yaml", "line": 5, "column": 7}
+		}],
+		"problems": []
+	}`, out)
+
+	out, code = runCLI(t, "diff", "--format", "json", "testdata/valid.yaml", "testdata/invalid.yaml")
+	require.Equal(t, 2, code, "Schemas that fail to parse should exit with a different status than breaking changes")
+	require.JSONEq(t, `{
+		"breaking": false,
+		"changes": [],
+		"problems": [
+			{"file": "testdata/invalid.yaml", "line": 5, "column": 7, "message": "Type User is not defined for `+"`author`"+`"},
+			{"file": "testdata/invalid.yaml", "line": 8, "column": 3, "message": "`+"`response`"+` is not defined for GET /posts"}
+		]
+	}`, out)
+
+	out, code = runCLI(t, "diff", "--format", "json", "testdata/valid.yaml")
+	require.Equal(t, 2, code)
+	require.JSONEq(t, `{
+		"breaking": false,
+		"changes": [],
+		"problems": [{"file": "testdata/valid.yaml", "message": "Expected an old and a new schema to compare"}]
+	}`, out)
+
+	out, code = runCLI(t, "diff", "testdata/valid.yaml", "testdata/missing.yaml")
+	require.Equal(t, 2, code)
+	require.Equal(t, "error: Failed to parse the new schema: open testdata/missing.yaml: no such file or directory\n", out)
+
+	out, code = runCLI(t, "diff", "--format", "xml", "testdata/valid.yaml", "testdata/changed.yaml")
+	require.Equal(t, 2, code)
+	require.Equal(t, "error: Unknown format xml, expected text or json\n", out)
+}
+
+// runCLI runs the CLI with args, returning what it printed and the status it
+// exits with. Errors are printed like main does.
+func runCLI(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+
+	out := new(bytes.Buffer)
+	app := newApp()
+	app.Writer = out
+	app.ErrWriter = out
+	app.ExitErrHandler = func(*cli.Context, error) {}
+
+	err := app.Run(append([]string{"overtime"}, args...))
+
+	var exitErr cli.ExitCoder
+	switch {
+	case err == nil:
+		return out.String(), 0
+	case errors.As(err, &exitErr):
+		if message := err.Error(); message != "" {
+			fmt.Fprintln(out, message)
+		}
+
+		return out.String(), exitErr.ExitCode()
+	default:
+		fmt.Fprintf(out, "error: %v\n", err)
+		return out.String(), 1
+	}
+}
+
+// chdir changes the working directory for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(cwd))
+	})
+}